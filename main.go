@@ -1,15 +1,12 @@
-// @APIVersion 1.0.0
-// @APITitle Swagger IBM Cloud Provider API
-// @APIDescription Swagger IBM Cloud Provider API
-// @Contact sakshiag@in.ibm.com
-
 package main
 
 import (
+	"crypto/tls"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 
@@ -19,7 +16,12 @@ import (
 	mgo "gopkg.in/mgo.v2"
 )
 
-var staticContent = flag.String("staticPath", "./swagger/swagger-ui", "Path to folder with Swagger UI")
+var configFile = flag.String("config", os.Getenv("CONFIG_FILE"), "YAML configuration file (CONFIG_FILE)")
+
+var configFlags = utils.RegisterConfigFlags(flag.CommandLine)
+
+//config is the configuration the server runs with.
+var config utils.Config
 
 func IndexHandler(w http.ResponseWriter, r *http.Request) {
 	isJsonRequest := false
@@ -34,89 +36,274 @@ func IndexHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if isJsonRequest {
-		w.Write([]byte(resourceListingJson))
+		http.Redirect(w, r, "/openapi.json", http.StatusFound)
 	} else {
 		http.Redirect(w, r, "/swagger-ui/", http.StatusFound)
 	}
 }
 
-func ApiDescriptionHandler(w http.ResponseWriter, r *http.Request) {
-	apiKey := strings.Trim(r.RequestURI, "/")
+func main() {
 
-	if json, ok := apiDescriptionsJson[apiKey]; ok {
-		w.Write([]byte(json))
-	} else {
-		w.WriteHeader(http.StatusNotFound)
+	flag.Parse()
+
+	// The configuration errors are logged as they are, the logging is not
+	// configured yet.
+	var err error
+	config, err = utils.LoadConfig(*configFile, configFlags)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = utils.Configure(config)
+	if err != nil {
+		log.Fatal(err)
 	}
-}
 
-func main() {
+	if flag.Arg(0) == "migrate-mongo" {
+		migrateMongo()
+		return
+	}
 
-	session, err := mgo.Dial("localhost")
-	if err != nil {
-		panic(err)
+	// Without MongoDB the configurations, actions, grants and API keys are
+	// kept in the store, the other features keep their records in MongoDB.
+	var session *mgo.Session
+	switch config.Storage.Kind {
+	case "mongo":
+		session = dialMongo()
+		defer session.Close()
+
+		if err := ensureIndex(session); err != nil {
+			utils.Fatal("Couldn't create the indexes of MongoDB", "error", err, "mongo_db", config.Storage.MongoDB)
+		}
+		utils.SetStore(utils.NewMongoStore(session))
+	case "memory":
+		utils.Log.Warn("Using the memory store, configurations, actions, grants, API keys and the audit log are lost when the server stops")
+		utils.SetStore(utils.NewMemoryStore())
+	default:
+		utils.SetStore(openSQLStore())
+	}
+	if session == nil {
+		utils.Log.Warn("Drift detection, schedules, ttl, git triggers, webhooks and notification channels need the mongo store, they are disabled",
+			"store", config.Storage.Kind)
 	}
-	defer session.Close()
 
-	session.SetMode(mgo.Monotonic, true)
-	ensureIndex(session)
+	r := newRouter(session)
+	if err := utils.CheckAPIOperations(r); err != nil {
+		utils.Log.Warn("The OpenAPI document is incomplete", "error", err)
+	}
 
-	var port int
-	flag.IntVar(&port, "p", 9080, "Port on which this server listens")
-	flag.Parse()
+	if session != nil {
+		utils.StartDriftScheduler(session)
+		utils.StartActionScheduler(session)
+		utils.StartTTLReaper(session)
+		utils.StartWebhookRetrier(session)
+	}
+
+	utils.Log.Info("Server will listen", "port", config.Port, "tls", config.TLS.CertFile != "", "store", config.Storage.Kind)
+	addr := fmt.Sprintf(":%d", config.Port)
+	if config.TLS.CertFile != "" {
+		// endless keeps the listening socket across graceful restarts, the
+		// certificate is served by the reloading tls config.
+		var tlsConfig *tls.Config
+		tlsConfig, err = utils.ServerTLSConfig(config.TLS)
+		if err != nil {
+			utils.Fatal("Couldn't load the tls configuration", "error", err)
+		}
+		srv := endless.NewServer(addr, r)
+		srv.TLSConfig = tlsConfig
+		err = srv.ListenAndServeTLS(config.TLS.CertFile, config.TLS.KeyFile)
+	} else {
+		err = endless.ListenAndServe(addr, r)
+	}
+	if err != nil {
+		utils.Log.Error("Couldn't start the server", "error", err)
+	}
+	utils.ShutdownTracing()
+}
+
+//newRouter registers the routes, a nil session disables the features needing MongoDB.
+func newRouter(session *mgo.Session) *mux.Router {
 	r := mux.NewRouter()
 
 	r.HandleFunc("/", IndexHandler)
 
-	r.PathPrefix("/swagger-ui").Handler(http.StripPrefix("/swagger-ui", http.FileServer(http.Dir(*staticContent))))
+	r.PathPrefix("/swagger-ui").Handler(http.StripPrefix("/swagger-ui", http.FileServer(http.Dir(config.Dirs.SwaggerUI))))
 
-	for apiKey := range apiDescriptionsJson {
-		log.Println("sdsadsadsada", apiKey)
-		r.HandleFunc("/"+apiKey, ApiDescriptionHandler)
-	}
+	r.HandleFunc("/healthz", utils.HealthHandler).Methods("GET")
+
+	r.HandleFunc("/readyz", utils.ReadyHandler(config.Storage.Kind)).Methods("GET")
+
+	r.HandleFunc("/version", utils.VersionHandler).Methods("GET")
+
+	r.HandleFunc("/metrics", utils.MetricsHandler).Methods("GET")
+
+	r.HandleFunc("/openapi.json", utils.OpenAPIHandler(r)).Methods("GET")
+
+	r.Use(utils.TracingMiddleware)
+
+	r.Use(utils.LoggingMiddleware)
+
+	r.Use(utils.MetricsMiddleware)
+
+	r.Use(utils.AuditMiddleware())
+
+	r.Use(utils.ValidateMiddleware)
+
+	r.Use(utils.AuthMiddleware())
+
+	r.Use(utils.TimeoutMiddleware(config.Timeouts.HTTP, config.Timeouts.Routes))
 
 	r.HandleFunc("/v1/configuration", utils.ConfHandler(session)).Methods("POST")
 
-	r.HandleFunc("/v1/configuration/{repo_name}", utils.ConfDeleteHandler).Methods("DELETE")
+	r.HandleFunc("/v1/configuration/{repo_name}", utils.RequireRole(utils.RoleAdmin, utils.ConfDeleteHandler(session))).Methods("DELETE")
+
+	r.HandleFunc("/v1/configuration/{repo_name}/settings", utils.RequireRole(utils.RoleViewer, utils.ConfigSettingsHandler(session))).Methods("GET")
+
+	r.HandleFunc("/v1/configuration/{repo_name}/settings", utils.RequireRole(utils.RoleAdmin, utils.ConfigSettingsUpdateHandler(session))).Methods("PUT")
+
+	r.HandleFunc("/v1/configuration/{repo_name}/plan", utils.RequireRole(utils.RolePlanner, utils.PlanHandler(session))).Methods("POST")
 
-	r.HandleFunc("/v1/configuration/{repo_name}/plan", utils.PlanHandler(session)).Methods("POST")
+	r.HandleFunc("/v1/configuration/{repo_name}/show", utils.RequireRole(utils.RolePlanner, utils.ShowHandler(session))).Methods("POST")
 
-	r.HandleFunc("/v1/configuration/{repo_name}/show", utils.ShowHandler(session)).Methods("POST")
+	r.HandleFunc("/v1/configuration/{repo_name}/apply", utils.RequireRole(utils.RoleOperator, utils.ApplyHandler(session))).Methods("POST")
 
-	r.HandleFunc("/v1/configuration/{repo_name}/apply", utils.ApplyHandler(session)).Methods("POST")
+	r.HandleFunc("/v1/configuration/{repo_name}/destroy", utils.RequireRole(utils.RoleAdmin, utils.DestroyHandler(session))).Methods("POST")
 
-	r.HandleFunc("/v1/configuration/{repo_name}/destroy", utils.DestroyHandler(session)).Methods("POST")
+	r.HandleFunc("/v1/apikeys", utils.APIKeyCreateHandler).Methods("POST")
 
-	r.HandleFunc("/v1/configuration/{repo_name}/{action}/{actionID}/log", utils.LogHandler).Methods("GET")
+	r.HandleFunc("/v1/apikeys", utils.APIKeyListHandler).Methods("GET")
 
-	r.HandleFunc("/v1/configuration/{repo_name}/{action}/{actionID}/status", utils.StatusHandler(session)).Methods("GET")
+	r.HandleFunc("/v1/apikeys/{key_id}", utils.APIKeyRevokeHandler).Methods("DELETE")
 
-	r.HandleFunc("/v1/configuration/{repo_name}/{action}/{log_file}", utils.ViewLogHandler)
+	r.HandleFunc("/v1/grants", utils.RequireRole(utils.RoleAdmin, utils.GrantCreateHandler)).Methods("POST")
 
-	r.HandleFunc("/v1/configuration/{repo_name}/{action}", utils.GetActionDetailsHandler(session)).Methods("GET")
+	r.HandleFunc("/v1/grants", utils.RequireRole(utils.RoleAdmin, utils.GrantListHandler)).Methods("GET")
 
-	fmt.Println("Server will listen at port", port)
-	muxWithMiddlewares := http.TimeoutHandler(r, time.Second*60, "Timeout!")
-	err = endless.ListenAndServe(fmt.Sprintf(":%d", port), muxWithMiddlewares)
+	r.HandleFunc("/v1/grants/{subject}", utils.RequireRole(utils.RoleAdmin, utils.GrantDeleteHandler)).Methods("DELETE")
+
+	r.HandleFunc("/v1/configuration/{repo_name}/grants", utils.RequireRole(utils.RoleAdmin, utils.GrantCreateHandler)).Methods("POST")
+
+	r.HandleFunc("/v1/configuration/{repo_name}/grants", utils.RequireRole(utils.RoleAdmin, utils.GrantListHandler)).Methods("GET")
+
+	r.HandleFunc("/v1/configuration/{repo_name}/grants/{subject}", utils.RequireRole(utils.RoleAdmin, utils.GrantDeleteHandler)).Methods("DELETE")
+
+	// These features keep their records in MongoDB, with another store their
+	// routes answer 501.
+	r.HandleFunc("/v1/configuration/{repo_name}/drift", utils.MongoOnly(session, "drift detection", utils.RequireRole(utils.RoleOperator, utils.DriftConfigHandler(session)))).Methods("PUT")
+
+	r.HandleFunc("/v1/configuration/{repo_name}/drift", utils.MongoOnly(session, "drift detection", utils.RequireRole(utils.RoleViewer, utils.DriftStatusHandler(session)))).Methods("GET")
+
+	r.HandleFunc("/v1/configuration/{repo_name}/schedules", utils.MongoOnly(session, "schedules", utils.RequireRole(utils.RolePlanner, utils.ScheduleCreateHandler(session)))).Methods("POST")
+
+	r.HandleFunc("/v1/configuration/{repo_name}/schedules", utils.MongoOnly(session, "schedules", utils.RequireRole(utils.RoleViewer, utils.ScheduleListHandler(session)))).Methods("GET")
+
+	r.HandleFunc("/v1/configuration/{repo_name}/schedules/{schedule_id}", utils.MongoOnly(session, "schedules", utils.RequireRole(utils.RoleOperator, utils.ScheduleDeleteHandler(session)))).Methods("DELETE")
+
+	r.HandleFunc("/v1/configuration/{repo_name}/ttl", utils.MongoOnly(session, "ttl", utils.RequireRole(utils.RoleViewer, utils.TTLHandler(session)))).Methods("GET")
+
+	r.HandleFunc("/v1/configuration/{repo_name}/ttl/extend", utils.MongoOnly(session, "ttl", utils.RequireRole(utils.RoleOperator, utils.TTLExtendHandler(session)))).Methods("POST")
+
+	r.HandleFunc("/v1/expiring", utils.MongoOnly(session, "ttl", utils.ExpiringHandler(session))).Methods("GET")
+
+	r.HandleFunc("/v1/configuration/{repo_name}/trigger", utils.MongoOnly(session, "git triggers", utils.RequireRole(utils.RoleOperator, utils.GitTriggerHandler(session)))).Methods("PUT")
+
+	r.HandleFunc("/v1/configuration/{repo_name}/trigger", utils.MongoOnly(session, "git triggers", utils.RequireRole(utils.RoleOperator, utils.GitTriggerDeleteHandler(session)))).Methods("DELETE")
+
+	r.HandleFunc("/v1/webhooks/{provider}", utils.MongoOnly(session, "git triggers", utils.GitWebhookHandler(session))).Methods("POST")
+
+	r.HandleFunc("/v1/configuration/{repo_name}/webhooks", utils.MongoOnly(session, "webhooks", utils.RequireRole(utils.RoleOperator, utils.WebhookCreateHandler(session)))).Methods("POST")
+
+	r.HandleFunc("/v1/configuration/{repo_name}/webhooks", utils.MongoOnly(session, "webhooks", utils.RequireRole(utils.RoleViewer, utils.WebhookListHandler(session)))).Methods("GET")
+
+	r.HandleFunc("/v1/configuration/{repo_name}/webhooks/{webhook_id}", utils.MongoOnly(session, "webhooks", utils.RequireRole(utils.RoleOperator, utils.WebhookDeleteHandler(session)))).Methods("DELETE")
+
+	r.HandleFunc("/v1/configuration/{repo_name}/webhooks/{webhook_id}/deliveries", utils.MongoOnly(session, "webhooks", utils.RequireRole(utils.RoleViewer, utils.WebhookDeliveriesHandler(session)))).Methods("GET")
+
+	r.HandleFunc("/v1/configuration/{repo_name}/channels", utils.MongoOnly(session, "notification channels", utils.RequireRole(utils.RoleOperator, utils.ChannelCreateHandler(session)))).Methods("POST")
+
+	r.HandleFunc("/v1/configuration/{repo_name}/channels", utils.MongoOnly(session, "notification channels", utils.RequireRole(utils.RoleViewer, utils.ChannelListHandler(session)))).Methods("GET")
+
+	r.HandleFunc("/v1/configuration/{repo_name}/channels/{channel_id}", utils.MongoOnly(session, "notification channels", utils.RequireRole(utils.RoleOperator, utils.ChannelUpdateHandler(session)))).Methods("PUT")
+
+	r.HandleFunc("/v1/configuration/{repo_name}/channels/{channel_id}", utils.MongoOnly(session, "notification channels", utils.RequireRole(utils.RoleOperator, utils.ChannelDeleteHandler(session)))).Methods("DELETE")
+
+	r.HandleFunc("/v1/audit", utils.RequireRole(utils.RoleAdmin, utils.AuditQueryHandler)).Methods("GET")
+
+	r.HandleFunc("/v1/audit/export", utils.RequireRole(utils.RoleAdmin, utils.AuditExportHandler)).Methods("GET")
+
+	r.HandleFunc("/v1/configuration/{repo_name}/{action}/{actionID}/log", utils.RequireRole(utils.RoleViewer, utils.LogHandler)).Methods("GET")
+
+	r.HandleFunc("/v1/configuration/{repo_name}/{action}/{actionID}/status", utils.RequireRole(utils.RoleViewer, utils.StatusHandler(session))).Methods("GET")
+
+	r.HandleFunc("/v1/configuration/{repo_name}/{action}/{log_file}", utils.RequireRole(utils.RoleViewer, utils.ViewLogHandler))
+
+	r.HandleFunc("/v1/configuration/{repo_name}/{action}", utils.RequireRole(utils.RoleViewer, utils.GetActionDetailsHandler(session))).Methods("GET")
+
+	return r
+}
+
+func dialMongo() *mgo.Session {
+	session, err := mgo.Dial(config.Storage.MongoURL)
+	if err != nil {
+		utils.Fatal("Couldn't connect to MongoDB", "mongo_url", config.Storage.MongoURL, "error", err)
+	}
+	session.SetMode(mgo.Monotonic, true)
+	utils.SetDBName(config.Storage.MongoDB)
+	return session
+}
+
+func openSQLStore() utils.Store {
+	drivers := map[string]string{"sqlite": "sqlite3", "postgres": "postgres"}
+	store, err := utils.NewSQLStore(drivers[config.Storage.Kind], config.Storage.SQLDSN)
 	if err != nil {
-		fmt.Printf("Couldn't start the server %v", err)
+		utils.Fatal("Couldn't open the store", "store", config.Storage.Kind, "error", err)
 	}
+	return store
 }
 
-func ensureIndex(s *mgo.Session) {
+//migrateMongo copies the actions, configurations, grants and API keys of
+//MongoDB into the sql store, with the server stopped.
+func migrateMongo() {
+	if config.Storage.Kind == "mongo" || config.Storage.Kind == "memory" {
+		utils.Fatal("migrate-mongo copies into a sql store, run it with -store sqlite or -store postgres")
+	}
+	store := openSQLStore()
+	session := dialMongo()
+	defer session.Close()
+
+	n, err := utils.MigrateMongo(session, store)
+	if err != nil {
+		utils.Fatal("Migration failed", "actions", n.Actions, "configurations", n.Configurations, "grants", n.Grants,
+			"api_keys", n.APIKeys, "error", err)
+	}
+	utils.Log.Info("Copied the actions, configurations, grants and API keys of MongoDB", "actions", n.Actions,
+		"configurations", n.Configurations, "grants", n.Grants, "api_keys", n.APIKeys,
+		"mongo_url", config.Storage.MongoURL, "mongo_db", config.Storage.MongoDB)
+}
+
+//ensureIndex creates the indexes of the collections, an error names the
+//collection whose index could not be created.
+func ensureIndex(s *mgo.Session) error {
 	session := s.Copy()
 	defer session.Close()
-	c := session.DB("action").C("actionDetails")
 
-	index := mgo.Index{
-		Key:        []string{"actionid"},
-		Unique:     true,
-		DropDups:   true,
-		Background: true,
-		Sparse:     true,
+	indexes := []struct {
+		collection string
+		index      mgo.Index
+	}{
+		{"actionDetails", mgo.Index{Key: []string{"actionid"}, Unique: true, DropDups: true, Background: true, Sparse: true}},
+		{"configurations", mgo.Index{Key: []string{"configname"}, Unique: true, Background: true}},
+		{"apiKeys", mgo.Index{Key: []string{"keyid"}, Unique: true, Background: true}},
+		{"auditLog", mgo.Index{Key: []string{"timestamp"}, Background: true}},
+		{"auditLog", mgo.Index{Key: []string{"subject", "timestamp"}, Background: true}},
+		{"auditLog", mgo.Index{Key: []string{"configname", "timestamp"}, Background: true}},
+		{"destroyConfirmations", mgo.Index{Key: []string{"expiresat"}, ExpireAfter: time.Second, Background: true}},
+		{"grants", mgo.Index{Key: []string{"configname", "subject"}, Unique: true, Background: true}},
 	}
-	err := c.EnsureIndex(index)
-	if err != nil {
-		panic(err)
+	for _, i := range indexes {
+		err := session.DB(config.Storage.MongoDB).C(i.collection).EnsureIndex(i.index)
+		if err != nil {
+			return fmt.Errorf("index %v of %s: %v", i.index.Key, i.collection, err)
+		}
 	}
+	return nil
 }
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/terraform-provider-ibm-api/utils"
+)
+
+//TestRoutesDocumented fails when a route is added without its OpenAPI operation.
+func TestRoutesDocumented(t *testing.T) {
+	if err := utils.CheckAPIOperations(newRouter(nil)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,262 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"time"
+
+	mgo "gopkg.in/mgo.v2"
+)
+
+// actionRunner performs a terraform action for a configuration. ctx holds
+// the span of the action.
+type actionRunner func(ctx context.Context, confDir, repoName, randomID string) error
+
+// actionRunners maps the action names accepted by the API to the terraform
+// command that performs them.
+// Each runs in a workspace of its own, see sandbox.go.
+var actionRunners = map[string]actionRunner{
+	"plan": func(ctx context.Context, confDir, repoName, randomID string) error {
+		pullRepo(ctx, repoName)
+		return inWorkspace(confDir, repoName, randomID, func(ws *workspace) error {
+			return TerraformPlan(ws.Dir, repoName, &planTimeOut, randomID)
+		})
+	},
+	"apply": func(ctx context.Context, confDir, repoName, randomID string) error {
+		pullRepo(ctx, repoName)
+		return inWorkspace(confDir, repoName, randomID, func(ws *workspace) error {
+			return TerraformApply(ws.Dir, ws.StateDir, repoName, &planTimeOut, randomID)
+		})
+	},
+	"destroy": func(ctx context.Context, confDir, repoName, randomID string) error {
+		return inWorkspace(confDir, repoName, randomID, func(ws *workspace) error {
+			return TerraformDestroy(ws.Dir, ws.StateDir, repoName, &planTimeOut, randomID)
+		})
+	},
+	"show": func(ctx context.Context, confDir, repoName, randomID string) error {
+		return inWorkspace(confDir, repoName, randomID, func(ws *workspace) error {
+			return TerraformShow(ws.Dir, ws.StateDir, repoName, &planTimeOut, randomID)
+		})
+	},
+}
+
+// actionSlots limits the actions running at once, see setActionWorkers.
+var actionSlots chan struct{}
+
+// setActionWorkers lets n actions run at once, or any number for 0.
+func setActionWorkers(n int) {
+	actionSlots = nil
+	if n > 0 {
+		actionSlots = make(chan struct{}, n)
+	}
+}
+
+// acquireActionSlot waits for a free slot and returns its release.
+func acquireActionSlot() func() {
+	slots := actionSlots
+	if slots == nil {
+		return func() {}
+	}
+	slots <- struct{}{}
+	return func() { <-slots }
+}
+
+// runningActions are the contexts of the running actions by action ID, with
+// their span and log fields, so that the work done for an action is traced and
+// logged under it, and how many actions of each configuration run.
+var runningActions = struct {
+	sync.Mutex
+	m       map[string]context.Context
+	configs map[string]int
+}{m: make(map[string]context.Context), configs: make(map[string]int)}
+
+// beginAction returns the context of the action, traced under the span of ctx
+// and logged with its log fields, and the func ending it.
+func beginAction(ctx context.Context, action, configName, actionID string) (context.Context, func(err error)) {
+	// The action outlives the request, its context only keeps the span and
+	// the log fields of ctx.
+	fields := logFields(ctx)
+	ctx, s := startActionSpan(ctx, action, configName, actionID)
+	ctx = withLogFields(context.WithValue(ctx, logFieldsKey{}, fields), "config", configName, "action", action, "action_id", actionID)
+
+	runningActions.Lock()
+	runningActions.m[actionID] = ctx
+	runningActions.configs[configName]++
+	runningActions.Unlock()
+	return ctx, func(err error) {
+		runningActions.Lock()
+		delete(runningActions.m, actionID)
+		if runningActions.configs[configName]--; runningActions.configs[configName] == 0 {
+			delete(runningActions.configs, configName)
+		}
+		runningActions.Unlock()
+		s.finish(err)
+	}
+}
+
+// actionInProgress is whether an action of the configuration runs.
+func actionInProgress(configName string) bool {
+	runningActions.Lock()
+	defer runningActions.Unlock()
+	return runningActions.configs[configName] > 0
+}
+
+// actionContext returns the context of the action while it runs.
+func actionContext(actionID string) context.Context {
+	runningActions.Lock()
+	defer runningActions.Unlock()
+	if ctx, ok := runningActions.m[actionID]; ok {
+		return ctx
+	}
+	return context.Background()
+}
+
+func newActionID() string {
+	b := make([]byte, 10)
+	rand.Read(b)
+	return fmt.Sprintf("%x", b)
+}
+
+// noticeFilter rewrites the notice about an action before it is posted, it
+// returns false when nothing is to be posted.
+type noticeFilter func(notice *ActionNotice) bool
+
+// startAction records a new action for the configuration in the db and runs it
+// in the background. Progress is posted to slack with log links below logURL.
+// done, when not nil, is called with the finished action and its final status.
+// The action is traced under the span of ctx, the request starting it.
+func startAction(ctx context.Context, s *mgo.Session, repoName, action, logURL, webhook string, done func(ActionResponse)) ActionResponse {
+	return startActionWith(ctx, s, repoName, action, logURL, webhook, actionRunners[action], nil, done)
+}
+
+// startActionWith is startAction with a custom runner for the action. filter,
+// when not nil, decides which notices about the action are posted.
+func startActionWith(ctx context.Context, s *mgo.Session, repoName, action, logURL, webhook string, runner actionRunner, filter noticeFilter, done func(ActionResponse)) ActionResponse {
+	var actionResponse ActionResponse
+
+	confDir := configDir(repoName)
+	randomID := newActionID()
+
+	outURL := logURL + "/" + randomID + ".out"
+	errURL := logURL + "/" + randomID + ".err"
+
+	actionResponse.Action = action
+	actionResponse.ConfigName = repoName
+	actionResponse.ActionID = randomID
+	actionResponse.Timestamp = time.Now().Format("20060102150405")
+	actionResponse.Status = "In-Progress"
+
+	ctx, endAction := beginAction(ctx, action, repoName, randomID)
+
+	// Make an entry in the db
+	if err := store.InsertAction(actionResponse); err != nil {
+		Log.ErrorContext(ctx, "Failed to insert the action", "error", err)
+	}
+
+	notice := ActionNotice{
+		ConfigName: repoName,
+		Action:     action,
+		ActionID:   randomID,
+		Status:     "In-Progress",
+		GitRef:     gitRef(repoName),
+		OutputURL:  outURL,
+		ErrorURL:   errURL,
+	}
+
+	Log.InfoContext(ctx, "Action started")
+
+	// Post to slack that the action has started and the link logs. It is
+	// posted from the action's goroutine, a slow slack does not hold the
+	// request, and before the follow-up threaded under it.
+	first := notice
+	postFirst := filter == nil || filter(&first)
+	if postFirst {
+		notifyChannels(s, first)
+	}
+	publishEvent(s, actionEvent(EventActionStarted, actionResponse, outURL, errURL))
+
+	go func(result ActionResponse) {
+		var threadTS string
+		if postFirst {
+			threadTS = ResultToSlack(first, webhook, "")
+		}
+		actionsQueued.add(1)
+		_, queued := startSpan(ctx, "queued", spanKindInternal)
+		release := acquireActionSlot()
+		queued.finish(nil)
+		actionsQueued.add(-1)
+		actionsRunning.add(1)
+		started := time.Now()
+		result.Status = "Completed"
+		runErr := runner(ctx, confDir, repoName, randomID)
+		actionsRunning.add(-1)
+		release()
+		if runErr != nil {
+			Log.ErrorContext(ctx, "Action failed", "error", runErr)
+			result.Status = "Failed"
+		}
+		actionsTotal.inc(action, result.Status)
+		actionDuration.observe(time.Since(started).Seconds(), action, result.Status)
+
+		// Update the status in the db
+		err := store.UpdateActionStatus(randomID, result.Status)
+		if err != nil {
+			Log.ErrorContext(ctx, "Failed to update the action status", "error", err)
+		}
+
+		// Follow up in the thread of the first post, with the change counts
+		// or, on failure, the end of stderr.
+		stdout, stderr, _ := readLogFile(randomID)
+		notice.Status = result.Status
+		notice.GitRef = gitRef(repoName)
+		notice.Summary = changeSummary(stdout)
+		notice.Duration = time.Since(started)
+		if runErr != nil {
+			notice.StderrTail = tailLines(stderr, slackStderrLines)
+			if notice.StderrTail == "" {
+				notice.StderrTail = runErr.Error()
+			}
+		}
+		if filter == nil || filter(&notice) {
+			ResultToSlack(notice, webhook, threadTS)
+			notifyChannels(s, notice)
+		}
+
+		eventType := EventActionCompleted
+		if result.Status != "Completed" {
+			eventType = EventActionFailed
+		}
+		publishEvent(s, actionEvent(eventType, result, outURL, errURL))
+		recordAudit(AuditEntry{
+			Kind:       "action",
+			Subject:    "server",
+			Tenant:     tenantOfConfig(repoName),
+			Route:      action,
+			ConfigName: repoName,
+			ActionID:   randomID,
+			Result:     result.Status,
+			Timestamp:  time.Now(),
+		})
+		if done != nil {
+			done(result)
+		}
+		Log.InfoContext(ctx, "Action finished", "status", result.Status, "duration", time.Since(started))
+		endAction(runErr)
+	}(actionResponse)
+
+	return actionResponse
+}
+
+func actionEvent(eventType string, a ActionResponse, outURL, errURL string) Event {
+	return Event{
+		Type:       eventType,
+		ConfigName: a.ConfigName,
+		Action:     a.Action,
+		ActionID:   a.ActionID,
+		Status:     a.Status,
+		OutputURL:  outURL,
+		ErrorURL:   errURL,
+	}
+}
@@ -0,0 +1,246 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// auditQueryLimit caps the entries returned by the audit query endpoint.
+const auditQueryLimit = 1000
+
+// AuditEntry -
+type AuditEntry struct {
+	Kind       string    `json:"kind" description:"request for API calls, action for finished actions"`
+	Subject    string    `json:"subject" description:"Caller identity, server for actions"`
+	Tenant     string    `json:"tenant,omitempty"`
+	Method     string    `json:"method,omitempty"`
+	Route      string    `json:"route" description:"Route template of the request, or the action name"`
+	Path       string    `json:"path,omitempty"`
+	ConfigName string    `json:"config_name,omitempty"`
+	ActionID   string    `json:"action_id,omitempty"`
+	SourceIP   string    `json:"source_ip,omitempty"`
+	Status     int       `json:"status,omitempty" description:"HTTP status of the response"`
+	Result     string    `json:"result" description:"allowed, denied, failed, or the status of the action"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+type auditKey struct{}
+
+// noteAudit adds to the audit entry of the request what only the handler
+// knows, like the configuration it created or the action it started.
+func noteAudit(r *http.Request, configName, actionID string) {
+	entry, ok := r.Context().Value(auditKey{}).(*AuditEntry)
+	if !ok {
+		return
+	}
+	if configName != "" {
+		entry.ConfigName = configName
+	}
+	if actionID != "" {
+		entry.ActionID = actionID
+	}
+}
+
+// auditIdentity records the caller identity, and the configuration qualified
+// by its tenant, once the request is authenticated.
+func auditIdentity(r *http.Request, id Identity) {
+	if entry, ok := r.Context().Value(auditKey{}).(*AuditEntry); ok {
+		entry.Subject = id.Subject
+		entry.Tenant = id.Tenant
+		if repoName, ok := mux.Vars(r)["repo_name"]; ok {
+			entry.ConfigName = qualifiedName(id.Tenant, repoName)
+		}
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = 200
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// AuditMiddleware records an audit entry for every request once it has been
+// served. It must run before the authentication middleware so that rejected
+// requests are recorded too.
+func AuditMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isPublicPath(r.URL.Path) && r.Method == "GET" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			entry := &AuditEntry{
+				Kind:       "request",
+				Method:     r.Method,
+				Route:      r.URL.Path,
+				Path:       r.URL.Path,
+				ConfigName: mux.Vars(r)["repo_name"],
+				SourceIP:   sourceIP(r),
+				Timestamp:  time.Now(),
+			}
+			if route := mux.CurrentRoute(r); route != nil {
+				if tpl, err := route.GetPathTemplate(); err == nil {
+					entry.Route = tpl
+				}
+			}
+
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), auditKey{}, entry)))
+
+			entry.Status = rec.status
+			if entry.Status == 0 {
+				entry.Status = 200
+			}
+			switch {
+			case entry.Status == 401 || entry.Status == 403:
+				entry.Result = "denied"
+			case entry.Status >= 400:
+				entry.Result = "failed"
+			default:
+				entry.Result = "allowed"
+			}
+			recordAudit(*entry)
+		})
+	}
+}
+
+func sourceIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// recordAudit appends the entry to the audit log of the store.
+func recordAudit(entry AuditEntry) {
+	err := store.InsertAuditEntry(entry)
+	if err != nil {
+		Log.Error("Failed to record the audit entry", "config", entry.ConfigName, "action_id", entry.ActionID, "error", err)
+	}
+}
+
+// AuditQuery selects entries of the audit log.
+type AuditQuery struct {
+	//Tenant of the entries, all of them when empty.
+	Tenant     string
+	Subject    string
+	ConfigName string
+	//From and To, when set, bound the time of the entries, To excluded.
+	From, To time.Time
+	//NewestFirst sorts the entries newest first, else oldest first.
+	NewestFirst bool
+	//Limit caps the number of entries, 0 for no limit.
+	Limit int
+}
+
+// matches tells whether the entry is selected by the query. Entries
+// recorded before tenants existed belong to the default tenant.
+func (q AuditQuery) matches(e AuditEntry) bool {
+	tenant := e.Tenant
+	if tenant == "" {
+		tenant = defaultTenant
+	}
+	return (q.Tenant == "" || q.Tenant == tenant) &&
+		(q.Subject == "" || q.Subject == e.Subject) &&
+		(q.ConfigName == "" || q.ConfigName == e.ConfigName) &&
+		(q.From.IsZero() || !e.Timestamp.Before(q.From)) &&
+		(q.To.IsZero() || e.Timestamp.Before(q.To))
+}
+
+// auditQuery builds the query from the user, configuration, from and to
+// query parameters, times in RFC 3339, within the tenant of the caller.
+// Unauthenticated requests are in the default tenant.
+func auditQuery(r *http.Request) (AuditQuery, error) {
+	params := r.URL.Query()
+	q := AuditQuery{Tenant: tenantOf(r), Subject: params.Get("user")}
+	if v := params.Get("configuration"); v != "" {
+		q.ConfigName = qualifiedName(q.Tenant, v)
+	}
+	for param, t := range map[string]*time.Time{"from": &q.From, "to": &q.To} {
+		if v := params.Get(param); v != "" {
+			var err error
+			*t, err = time.Parse(time.RFC3339, v)
+			if err != nil {
+				return q, err
+			}
+		}
+	}
+	return q, nil
+}
+
+// AuditQueryHandler handles request to query the audit log.
+func AuditQueryHandler(w http.ResponseWriter, r *http.Request) {
+	q, err := auditQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	q.NewestFirst = true
+	q.Limit = auditQueryLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		q.Limit, err = strconv.Atoi(v)
+		if err != nil || q.Limit < 1 || q.Limit > auditQueryLimit {
+			http.Error(w, "The limit must be between 1 and 1000", 400)
+			return
+		}
+	}
+
+	entries := []AuditEntry{}
+	err = store.FindAuditEntries(q, func(e AuditEntry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	output, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.Write(output)
+}
+
+// AuditExportHandler handles request to export the audit log as JSON Lines.
+func AuditExportHandler(w http.ResponseWriter, r *http.Request) {
+	q, err := auditQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	w.Header().Set("content-type", "application/x-ndjson")
+	w.Header().Set("content-disposition", `attachment; filename="audit.jsonl"`)
+
+	enc := json.NewEncoder(w)
+	err = store.FindAuditEntries(q, func(e AuditEntry) error {
+		return enc.Encode(e)
+	})
+	if err != nil {
+		Log.ErrorContext(r.Context(), "Failed to export the audit log", "error", err)
+	}
+}
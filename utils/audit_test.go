@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestAuditLog(t *testing.T) {
+	saved := store
+	defer func() { store = saved }()
+	SetStore(NewMemoryStore())
+	defer func(key string) { bootstrapAPIKey = key }(bootstrapAPIKey)
+	bootstrapAPIKey = "boot"
+
+	r := mux.NewRouter()
+	r.Use(AuditMiddleware())
+	r.Use(AuthMiddleware())
+	r.HandleFunc("/v1/configuration/{repo_name}", func(w http.ResponseWriter, r *http.Request) {}).Methods("GET")
+	r.HandleFunc("/v1/audit", RequireRole(RoleAdmin, AuditQueryHandler)).Methods("GET")
+	r.HandleFunc("/v1/audit/export", RequireRole(RoleAdmin, AuditExportHandler)).Methods("GET")
+
+	serve := func(path, key, tenant string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		if tenant != "" {
+			req.Header.Set(tenantHeader, tenant)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	serve("/v1/configuration/web", "boot", "")
+	serve("/v1/configuration/web", "wrong", "")
+	serve("/v1/configuration/web", "boot", "acme")
+
+	w := serve("/v1/audit", "boot", "")
+	var entries []AuditEntry
+	if err := json.Unmarshal(w.Body.Bytes(), &entries); err != nil {
+		t.Fatalf("%d %s: %v", w.Code, w.Body, err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries in the default tenant, want 2: %+v", len(entries), entries)
+	}
+	denied, allowed := entries[0], entries[1]
+	if denied.Result != "denied" || denied.Status != 401 || denied.Subject != "" {
+		t.Errorf("the rejected request is recorded as %+v", denied)
+	}
+	if allowed.Result != "allowed" || allowed.Subject != bootstrapSubject || allowed.Route != "/v1/configuration/{repo_name}" || allowed.ConfigName != "web" {
+		t.Errorf("the request is recorded as %+v", allowed)
+	}
+
+	w = serve("/v1/audit?limit=0", "boot", "")
+	if w.Code != 400 {
+		t.Errorf("limit=0 answered %d, want 400", w.Code)
+	}
+
+	// The entries of a tenant are only seen from it, the query of the default
+	// tenant is in the export.
+	w = serve("/v1/audit/export?user="+bootstrapSubject, "boot", "acme")
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	var entry AuditEntry
+	if len(lines) != 1 || json.Unmarshal([]byte(lines[0]), &entry) != nil || entry.ConfigName != "acme/web" || entry.Tenant != "acme" {
+		t.Errorf("the export of acme is %q", w.Body)
+	}
+	w = serve("/v1/audit/export", "boot", "")
+	if n := strings.Count(w.Body.String(), "\n"); n != 4 {
+		t.Errorf("the export of the default tenant has %d entries, want 4:\n%s", n, w.Body)
+	}
+}
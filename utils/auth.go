@@ -0,0 +1,339 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// authDisabled turns the authentication middleware off, for local development
+// only. Set by Configure, like the other settings of the authentication.
+var authDisabled bool
+
+// bootstrapAPIKey is accepted as an API key so that the first keys can be issued.
+var bootstrapAPIKey string
+
+// bootstrapSubject is the identity of callers presenting the bootstrap key.
+const bootstrapSubject = "bootstrap"
+
+// tokenValidator validates bearer tokens when JWKS_URL is set.
+var tokenValidator *jwtValidator
+
+// apiKeyPrefix marks the keys issued by this server.
+const apiKeyPrefix = "tfk"
+
+// Identity -
+type Identity struct {
+	Subject string `json:"subject" description:"User or service the request acts as"`
+	Method  string `json:"method" description:"api_key, jwt, client_certificate or bootstrap"`
+	KeyID   string `json:"key_id,omitempty"`
+	Tenant  string `json:"tenant" description:"Tenant the caller belongs to"`
+}
+
+type identityKey struct{}
+
+// IdentityFrom returns the caller identity the authentication middleware
+// attached to the request.
+func IdentityFrom(r *http.Request) (Identity, bool) {
+	id, ok := r.Context().Value(identityKey{}).(Identity)
+	return id, ok
+}
+
+func withIdentity(r *http.Request, id Identity) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), identityKey{}, id))
+}
+
+// APIKeyRequest -
+type APIKeyRequest struct {
+	Name    string `json:"name,required" description:"Name to recognise the key by"`
+	Subject string `json:"subject,omitempty" description:"Identity the key acts as. Only admins of all configurations may set it, otherwise it is the caller"`
+	Tenant  string `json:"tenant,omitempty" description:"Tenant of the key. Only the bootstrap key may set it, otherwise it is the caller's"`
+}
+
+// APIKey -
+type APIKey struct {
+	KeyID     string    `json:"key_id"`
+	Name      string    `json:"name"`
+	Subject   string    `json:"subject"`
+	Tenant    string    `json:"tenant"`
+	Hash      string    `json:"-"`
+	CreatedBy string    `json:"created_by"`
+	Created   time.Time `json:"created"`
+	Revoked   bool      `json:"revoked"`
+	RevokedAt time.Time `json:"revoked_at,omitempty"`
+}
+
+// APIKeyResponse -
+type APIKeyResponse struct {
+	APIKey
+	Key string `json:"key" description:"The API key. It is only returned when the key is created"`
+}
+
+// isPublicPath tells whether the path is served without credentials. Git
+// webhooks authenticate with their own signature, the health endpoints are
+// probed by the orchestrator and the metrics scraped by Prometheus. The swagger
+// ui needs the OpenAPI document before the user authorizes it.
+func isPublicPath(p string) bool {
+	return p == "/" || p == "/openapi.json" ||
+		p == "/healthz" || p == "/readyz" || p == "/version" || p == "/metrics" ||
+		strings.HasPrefix(p, "/swagger-ui") ||
+		strings.HasPrefix(p, "/v1/webhooks/")
+}
+
+// AuthMiddleware identifies the caller from an API key (X-API-Key header or
+// bearer token), a JWT bearer token or a client certificate and rejects
+// unauthenticated requests.
+func AuthMiddleware() func(http.Handler) http.Handler {
+	if !authDisabled && bootstrapAPIKey == "" && tokenValidator == nil {
+		Log.Warn("Neither BOOTSTRAP_API_KEY nor JWKS_URL is set, only existing API keys will be accepted")
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if authDisabled || isPublicPath(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			id, err := authenticate(r)
+			auditIdentity(r, id)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="terraform-provider-ibm-api"`)
+				http.Error(w, "Unauthorized: "+err.Error(), 401)
+				return
+			}
+			next.ServeHTTP(w, withIdentity(r, id))
+		})
+	}
+}
+
+func authenticate(r *http.Request) (Identity, error) {
+	credential := r.Header.Get("X-API-Key")
+	if credential == "" {
+		auth := r.Header.Get("Authorization")
+		if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+			credential = strings.TrimSpace(auth[7:])
+		}
+	}
+	if credential == "" {
+		if id, ok, err := certIdentity(r); ok {
+			return id, err
+		}
+		return Identity{}, fmt.Errorf("no API key, bearer token or client certificate")
+	}
+
+	if bootstrapAPIKey != "" && subtle.ConstantTimeCompare([]byte(credential), []byte(bootstrapAPIKey)) == 1 {
+		tenant := r.Header.Get(tenantHeader)
+		if tenant == "" {
+			tenant = defaultTenant
+		}
+		if err := validateTenant(tenant); err != nil {
+			return Identity{}, err
+		}
+		return Identity{Subject: bootstrapSubject, Method: "bootstrap", Tenant: tenant}, nil
+	}
+	if strings.HasPrefix(credential, apiKeyPrefix+"_") {
+		return authenticateAPIKey(credential)
+	}
+	if tokenValidator != nil && strings.Count(credential, ".") == 2 {
+		subject, tenant, err := tokenValidator.Validate(credential)
+		if err != nil {
+			return Identity{}, err
+		}
+		if tenant == "" {
+			tenant = defaultTenant
+		}
+		if err := validateTenant(tenant); err != nil {
+			return Identity{}, err
+		}
+		return Identity{Subject: subject, Method: "jwt", Tenant: tenant}, nil
+	}
+	return Identity{}, fmt.Errorf("invalid credentials")
+}
+
+// authenticateAPIKey looks the key up by the id embedded in it and compares
+// the hash of the whole key.
+func authenticateAPIKey(key string) (Identity, error) {
+	parts := strings.SplitN(key, "_", 3)
+	if len(parts) != 3 {
+		return Identity{}, fmt.Errorf("invalid credentials")
+	}
+
+	apiKey, err := store.FindAPIKey(parts[1])
+	if err != nil {
+		return Identity{}, fmt.Errorf("invalid credentials")
+	}
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(key)), []byte(apiKey.Hash)) != 1 {
+		return Identity{}, fmt.Errorf("invalid credentials")
+	}
+	if apiKey.Revoked {
+		return Identity{}, fmt.Errorf("the API key has been revoked")
+	}
+	tenant := apiKey.Tenant
+	if tenant == "" {
+		tenant = defaultTenant
+	}
+	return Identity{Subject: apiKey.Subject, Method: "api_key", KeyID: apiKey.KeyID, Tenant: tenant}, nil
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeyCreateHandler handles request to issue an API key.
+func APIKeyCreateHandler(w http.ResponseWriter, r *http.Request) {
+	caller, _ := IdentityFrom(r)
+
+	b, err := ioutil.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	var msg APIKeyRequest
+	err = json.Unmarshal(b, &msg)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	if msg.Name == "" {
+		http.Error(w, "EMPTY KEY NAME", 400)
+		return
+	}
+	subject := caller.Subject
+	if msg.Subject != "" && msg.Subject != caller.Subject {
+		role, err := roleOf(caller, tenantAll(caller.Tenant))
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		if role != RoleAdmin {
+			http.Error(w, "Forbidden: only admins of all configurations can issue keys for another subject", 403)
+			return
+		}
+		subject = msg.Subject
+	}
+	if subject == "" {
+		http.Error(w, "The key needs a subject", 400)
+		return
+	}
+	tenant := tenantOf(r)
+	if msg.Tenant != "" && msg.Tenant != tenant {
+		if caller.Method != "bootstrap" {
+			http.Error(w, "Forbidden: only the bootstrap key can issue keys for another tenant", 403)
+			return
+		}
+		if err := validateTenant(msg.Tenant); err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		tenant = msg.Tenant
+	}
+
+	secret := make([]byte, 32)
+	_, err = rand.Read(secret)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	keyID := newActionID()
+	key := apiKeyPrefix + "_" + keyID + "_" + hex.EncodeToString(secret)
+
+	var response APIKeyResponse
+	response.APIKey = APIKey{
+		KeyID:     keyID,
+		Name:      msg.Name,
+		Subject:   subject,
+		Tenant:    tenant,
+		Hash:      hashAPIKey(key),
+		CreatedBy: caller.Subject,
+		Created:   time.Now(),
+	}
+	response.Key = key
+
+	err = store.InsertAPIKey(response.APIKey)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	output, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(201)
+	w.Write(output)
+}
+
+// apiKeyScope limits the keys a caller can see and revoke to its own within
+// its tenant, except for the bootstrap key which sees them all. It returns the
+// tenant and subject of store.FindAPIKeys.
+func apiKeyScope(caller Identity) (tenant, subject string) {
+	if caller.Method == "bootstrap" {
+		return "", ""
+	}
+	return caller.Tenant, caller.Subject
+}
+
+// inAPIKeyScope tells whether the caller can see and revoke the key.
+func inAPIKeyScope(caller Identity, k APIKey) bool {
+	tenant, subject := apiKeyScope(caller)
+	return tenant == "" || keyOfTenant(k, tenant) && (k.Subject == subject || k.CreatedBy == subject)
+}
+
+// APIKeyListHandler handles request to list API keys.
+func APIKeyListHandler(w http.ResponseWriter, r *http.Request) {
+	caller, _ := IdentityFrom(r)
+
+	keys, err := store.FindAPIKeys(apiKeyScope(caller))
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	if keys == nil {
+		keys = []APIKey{}
+	}
+
+	output, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.Write(output)
+}
+
+// APIKeyRevokeHandler handles request to revoke an API key.
+func APIKeyRevokeHandler(w http.ResponseWriter, r *http.Request) {
+	caller, _ := IdentityFrom(r)
+	keyID := mux.Vars(r)["key_id"]
+
+	k, err := store.FindAPIKey(keyID)
+	if err == nil && !inAPIKeyScope(caller, k) {
+		err = ErrNotFound
+	}
+	if err == nil {
+		err = store.RevokeAPIKey(keyID, time.Now())
+	}
+	if err == ErrNotFound {
+		http.Error(w, "There is no API key for this request.", 404)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+}
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	saved := store
+	defer func() { store = saved }()
+	SetStore(NewMemoryStore())
+	defer func(key string) { bootstrapAPIKey = key }(bootstrapAPIKey)
+	bootstrapAPIKey = "boot"
+
+	key := apiKeyPrefix + "_0a1b2c_" + strings.Repeat("5e", 32)
+	revoked := apiKeyPrefix + "_3d4e5f_" + strings.Repeat("7a", 32)
+	store.InsertAPIKey(APIKey{KeyID: "0a1b2c", Subject: "alice", Tenant: "acme", Hash: hashAPIKey(key)})
+	store.InsertAPIKey(APIKey{KeyID: "3d4e5f", Subject: "bob", Hash: hashAPIKey(revoked)})
+	store.RevokeAPIKey("3d4e5f", time.Now())
+
+	tests := []struct {
+		name    string
+		header  string
+		value   string
+		tenant  string
+		want    Identity
+		wantErr string
+	}{
+		{"X-API-Key", "X-API-Key", key, "", Identity{Subject: "alice", Method: "api_key", KeyID: "0a1b2c", Tenant: "acme"}, ""},
+		{"bearer", "Authorization", "Bearer " + key, "", Identity{Subject: "alice", Method: "api_key", KeyID: "0a1b2c", Tenant: "acme"}, ""},
+		{"the tenant header is ignored", "X-API-Key", key, "other", Identity{Subject: "alice", Method: "api_key", KeyID: "0a1b2c", Tenant: "acme"}, ""},
+		{"wrong secret", "X-API-Key", apiKeyPrefix + "_0a1b2c_" + strings.Repeat("00", 32), "", Identity{}, "invalid credentials"},
+		{"unknown key id", "X-API-Key", apiKeyPrefix + "_ffffff_" + strings.Repeat("5e", 32), "", Identity{}, "invalid credentials"},
+		{"no secret", "X-API-Key", apiKeyPrefix + "_0a1b2c", "", Identity{}, "invalid credentials"},
+		{"key id of another key", "X-API-Key", apiKeyPrefix + "_3d4e5f_" + strings.Repeat("5e", 32), "", Identity{}, "invalid credentials"},
+		{"revoked", "X-API-Key", revoked, "", Identity{}, "revoked"},
+		{"bootstrap", "X-API-Key", "boot", "", Identity{Subject: bootstrapSubject, Method: "bootstrap", Tenant: defaultTenant}, ""},
+		{"bootstrap in a tenant", "X-API-Key", "boot", "acme", Identity{Subject: bootstrapSubject, Method: "bootstrap", Tenant: "acme"}, ""},
+		{"bootstrap in an invalid tenant", "X-API-Key", "boot", "../etc", Identity{}, "Invalid tenant"},
+		{"unknown credential", "X-API-Key", "secret", "", Identity{}, "invalid credentials"},
+		{"none", "", "", "", Identity{}, "no API key"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/v1/configuration", nil)
+		if tt.header != "" {
+			r.Header.Set(tt.header, tt.value)
+		}
+		if tt.tenant != "" {
+			r.Header.Set(tenantHeader, tt.tenant)
+		}
+		id, err := authenticate(r)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: got %+v, %v, want an error with %q", tt.name, id, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || id != tt.want {
+			t.Errorf("%s: got %+v, %v, want %+v", tt.name, id, err, tt.want)
+		}
+	}
+}
+
+func TestAuthenticateJWT(t *testing.T) {
+	j, srv := newTestJWKS(t)
+	defer srv.Close()
+	defer func(v *jwtValidator) { tokenValidator = v }(tokenValidator)
+	tokenValidator = newJWTValidator(srv.URL, "", "", "tenant")
+
+	exp := time.Now().Unix() + 300
+	tests := []struct {
+		name    string
+		token   string
+		want    Identity
+		wantErr string
+	}{
+		{"tenant claim", j.sign(t, "RS256", "rsa", map[string]interface{}{"sub": "alice", "exp": exp, "tenant": "acme"}), Identity{Subject: "alice", Method: "jwt", Tenant: "acme"}, ""},
+		{"default tenant", j.sign(t, "RS256", "rsa", map[string]interface{}{"sub": "alice", "exp": exp}), Identity{Subject: "alice", Method: "jwt", Tenant: defaultTenant}, ""},
+		{"invalid tenant", j.sign(t, "RS256", "rsa", map[string]interface{}{"sub": "alice", "exp": exp, "tenant": "Acme/.."}), Identity{}, "Invalid tenant"},
+		{"expired", j.sign(t, "RS256", "rsa", map[string]interface{}{"sub": "alice", "exp": exp - 3600}), Identity{}, "expired"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/v1/configuration", nil)
+		r.Header.Set("Authorization", "Bearer "+tt.token)
+		id, err := authenticate(r)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: got %+v, %v, want an error with %q", tt.name, id, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || id != tt.want {
+			t.Errorf("%s: got %+v, %v, want %+v", tt.name, id, err, tt.want)
+		}
+	}
+}
@@ -1,38 +1,53 @@
 package utils
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 )
 
-var stdouterr []byte
+// It will clone the git repo which contains the configuration file, for the tenant.
+func cloneRepo(ctx context.Context, msg ConfigRequest, tenant string) (stdouterr []byte, p string, err error) {
+	ctx, s := startSpan(ctx, "cloneRepo", spanKindInternal)
+	defer func() { s.finish(err) }()
 
-//It will clone the git repo which contains the configuration file.
-func cloneRepo(msg ConfigRequest) ([]byte, string, error) {
 	gitURL := msg.GitURL
-	urlPath, err := url.Parse(msg.GitURL)
+	p, err = configNameFromURL(gitURL)
 	if err != nil {
 		return nil, "", err
 	}
-	baseName := filepath.Base(urlPath.Path)
-	extName := filepath.Ext(urlPath.Path)
-	p := baseName[:len(baseName)-len(extName)]
-	if _, err := os.Stat(currentDir + "/" + p); err == nil {
-		stdouterr, err = pullRepo(p)
+	p = qualifiedName(tenant, p)
+	s.set("config.name", p)
+	if _, err := os.Stat(configDir(p)); err == nil {
+		stdouterr, err = pullRepo(ctx, p)
 
 	} else {
-		cmd := exec.Command("git", "clone", gitURL)
-		fmt.Println(cmd.Args)
+		// The directories of the tenant are created with its first configuration.
+		// The configurations hold their variables, only the server reads them.
+		err = os.MkdirAll(filepath.Dir(configDir(p)), 0700)
+		if err == nil {
+			err = os.MkdirAll(filepath.Dir(filepath.Join(stateDir, p)), 0700)
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		cmd := exec.Command("git", "clone", gitURL, configDir(p))
+		Log.DebugContext(ctx, "Running git", "args", cmd.Args)
 		cmd.Dir = currentDir
 		stdouterr, err = cmd.CombinedOutput()
 		if err != nil {
-			return nil, "", err
+			return stdouterr, "", err
+		}
+		err = os.Chmod(configDir(p), 0700)
+		if err != nil {
+			return stdouterr, "", err
 		}
 	}
-	path := currentDir + "/" + p + "/terraform.tfvars"
+	path := configDir(p) + "/terraform.tfvars"
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		createFile(msg, path)
 	} else {
@@ -43,7 +58,19 @@ func cloneRepo(msg ConfigRequest) ([]byte, string, error) {
 	return stdouterr, p, err
 }
 
-//It will create a vars file
+// configNameFromURL returns the name of the configuration cloned from the git url.
+func configNameFromURL(gitURL string) (string, error) {
+	urlPath, err := url.Parse(gitURL)
+	if err != nil {
+		return "", err
+	}
+	baseName := filepath.Base(urlPath.Path)
+	extName := filepath.Ext(urlPath.Path)
+	name := baseName[:len(baseName)-len(extName)]
+	return name, validateConfigName(name)
+}
+
+// It will create a vars file, readable by the server only
 func createFile(msg ConfigRequest, path string) {
 	// detect if file exists
 
@@ -51,7 +78,7 @@ func createFile(msg ConfigRequest, path string) {
 
 	// create file if not exists
 	if os.IsNotExist(err) {
-		file, err := os.Create(path)
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0600)
 		if err != nil {
 			return
 		}
@@ -61,32 +88,89 @@ func createFile(msg ConfigRequest, path string) {
 	writeFile(path, msg)
 }
 
-func pullRepo(repoName string) ([]byte, error) {
+func pullRepo(ctx context.Context, repoName string) (stdoutStderr []byte, err error) {
+	_, s := startSpan(ctx, "pullRepo", spanKindInternal)
+	s.set("config.name", repoName)
+	defer func() { s.finish(err) }()
+
 	cmd := exec.Command("git", "pull")
-	fmt.Println(cmd.Args)
-	cmd.Dir = currentDir + "/" + repoName
-	stdoutStderr, err := cmd.CombinedOutput()
+	Log.DebugContext(ctx, "Running git", "args", cmd.Args)
+	cmd.Dir = configDir(repoName)
+	stdoutStderr, err = cmd.CombinedOutput()
 	if err != nil {
 		return nil, err
 	}
 	return stdoutStderr, err
 }
 
-func removeRepo(path, repoName string) error {
-	removePath := filepath.Join(path, repoName)
+// addWorktree checks out ref, fetched from origin, into a detached worktree at dir.
+func addWorktree(repoName, ref, dir string) error {
+	logger := Log.With("config", repoName)
+	cmd := exec.Command("git", "fetch", "origin", ref)
+	logger.Debug("Running git", "args", cmd.Args)
+	cmd.Dir = configDir(repoName)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git fetch %s failed: %v: %s", ref, err, out)
+	}
+
+	cmd = exec.Command("git", "worktree", "add", "--detach", dir, "FETCH_HEAD")
+	logger.Debug("Running git", "args", cmd.Args)
+	cmd.Dir = configDir(repoName)
+	out, err = cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git worktree add failed: %v: %s", err, out)
+	}
+	return nil
+}
+
+func removeWorktree(repoName, dir string) error {
+	cmd := exec.Command("git", "worktree", "remove", "--force", dir)
+	Log.Debug("Running git", "config", repoName, "args", cmd.Args)
+	cmd.Dir = configDir(repoName)
+	_, err := cmd.CombinedOutput()
+	return err
+}
+
+// gitRef returns the branch and short commit checked out for the configuration,
+// e.g. master@1a2b3c4, or "" when it can not be read.
+func gitRef(repoName string) string {
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd.Dir = configDir(repoName)
+	branch, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	cmd = exec.Command("git", "rev-parse", "--short", "HEAD")
+	cmd.Dir = configDir(repoName)
+	commit, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(branch)) + "@" + strings.TrimSpace(string(commit))
+}
+
+func removeRepo(repoName string) error {
+	if err := validateStoredName(repoName); err != nil {
+		return err
+	}
+	removePath := configDir(repoName)
 	err := os.RemoveAll(removePath)
 	return err
 }
 
 func writeFile(path string, msg ConfigRequest) {
 	// open file using READ & WRITE permission
-	var file, err = os.OpenFile(path, os.O_RDWR, 0644)
+	var file, err = os.OpenFile(path, os.O_RDWR, 0600)
 	if err != nil {
 		return
 	}
 	defer file.Close()
 
 	variables := msg.VariableStore
+	if variables == nil {
+		return
+	}
 
 	for _, v := range *variables {
 		_, err = file.WriteString(v.Name + " = \"" + v.Value + "\" \n")
@@ -1,44 +1,23 @@
 package utils
 
-import (
-	"log"
-
-	mgo "gopkg.in/mgo.v2"
-	"gopkg.in/mgo.v2/bson"
-)
-
-//ResultToSlack will send result to slack
-func ResultToSlack(outURL, errURL, action, randomID, status, webhook string) {
-
-	m := ComposeSlackMessage(outURL, errURL, action, randomID, status)
-	m.PostToSlack(webhook)
-
-}
-
-//UpdateMongodb updates the status of the action.
-func UpdateMongodb(s *mgo.Session, actionID string, status string) error {
-	session := s.Copy()
-	defer session.Close()
-	c := session.DB("action").C("actionDetails")
-	err := c.Update(bson.M{"actionid": actionID}, bson.M{"$set": bson.M{"status": status}})
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-//InsertMongodb updates the status of the action.
-func InsertMongodb(s *mgo.Session, actionResponse ActionResponse) {
-	session := s.Copy()
-	defer session.Close()
-	c := session.DB("action").C("actionDetails")
-	err := c.Insert(actionResponse)
-	if err != nil {
-		if mgo.IsDup(err) {
-			return
+// ResultToSlack will send result to slack, threaded under threadTS when it is
+// set. It returns the ts of the posted message when slack reports one.
+// Without a webhook in the request the message goes to the slack channel or
+// webhook of the configuration's tenant, only the default tenant has the
+// server's default webhook. Nothing is sent when there is none, the
+// configuration's channels are notified instead.
+func ResultToSlack(n ActionNotice, webhook, threadTS string) string {
+	m := ComposeSlackMessage(n)
+	m.ThreadTS = threadTS
+	if webhook == "" {
+		m.Channel = slackChannelOf(n.ConfigName)
+		if m.Channel == "" && tenantOfConfig(n.ConfigName) == defaultTenant {
+			webhook = DefaultIncomingWebHook
 		}
-		log.Println("Failed insert action details : ", err)
-		return
 	}
+	if webhook == "" && (slackBotToken == "" || m.Channel == "") {
+		return ""
+	}
+	return m.PostToSlack(webhook)
+
 }
@@ -0,0 +1,611 @@
+package utils
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Config is the configuration of the server. Each setting is read from the
+// yaml file, then the environment, then the flags, the last one set wins.
+type Config struct {
+	Port          int                 `yaml:"port"`
+	Storage       StorageConfig       `yaml:"storage"`
+	Dirs          DirsConfig          `yaml:"dirs"`
+	Timeouts      TimeoutsConfig      `yaml:"timeouts"`
+	Workers       WorkersConfig       `yaml:"workers"`
+	TLS           TLSConfig           `yaml:"tls"`
+	Notifications NotificationsConfig `yaml:"notifications"`
+	Tracing       TracingConfig       `yaml:"tracing"`
+	Logging       LoggingConfig       `yaml:"logging"`
+	Auth          AuthConfig          `yaml:"auth"`
+	SCM           SCMConfig           `yaml:"scm"`
+	Sandbox       SandboxConfig       `yaml:"sandbox"`
+}
+
+// StorageConfig selects the store of configurations and actions.
+type StorageConfig struct {
+	Kind     string `yaml:"kind"`
+	MongoURL string `yaml:"mongo_url"`
+	MongoDB  string `yaml:"mongo_db"`
+	SQLDSN   string `yaml:"sql_dsn"`
+}
+
+// DirsConfig are the directories of the server.
+type DirsConfig struct {
+	Mount     string `yaml:"mount"`
+	SwaggerUI string `yaml:"swagger_ui"`
+}
+
+// TimeoutsConfig bound how long requests and terraform actions may run.
+type TimeoutsConfig struct {
+	HTTP time.Duration `yaml:"http"`
+	//Routes overrides HTTP and the budgets of routeTimeouts by route path
+	//template, 0 for no timeout.
+	Routes map[string]time.Duration `yaml:"routes"`
+	Action time.Duration            `yaml:"action"`
+}
+
+// WorkersConfig limits the work done concurrently.
+type WorkersConfig struct {
+	//Actions is how many terraform actions run at once, the others wait
+	//in a queue. 0 runs every action right away.
+	Actions int `yaml:"actions"`
+}
+
+// TLSConfig enables https when a certificate is set, and client
+// certificates signed by the client CA.
+type TLSConfig struct {
+	CertFile     string `yaml:"cert_file"`
+	KeyFile      string `yaml:"key_file"`
+	ClientCAFile string `yaml:"client_ca_file"`
+	//ClientAuth is optional, the other credentials are still accepted, or
+	//require, every connection presents a client certificate.
+	ClientAuth       string                    `yaml:"client_auth"`
+	ClientIdentities map[string]ClientIdentity `yaml:"client_identities"`
+	//ReloadInterval is how often the certificate files are checked for changes.
+	ReloadInterval time.Duration `yaml:"reload_interval"`
+}
+
+// NotificationsConfig are the default destinations of action notifications.
+type NotificationsConfig struct {
+	SlackWebhook  string `yaml:"slack_webhook"`
+	SlackBotToken string `yaml:"slack_bot_token"`
+	SlackChannel  string `yaml:"slack_channel"`
+	//SlackChannels are the channels of the other tenants, by tenant. The
+	//default webhook and channel are only posted to for the default tenant.
+	SlackChannels map[string]string `yaml:"slack_channels"`
+	SMTP          SMTPSettings      `yaml:"smtp"`
+	//WebhookAllowedNetworks are the CIDRs of the loopback, private and
+	//link-local addresses the webhooks may be delivered to, none by default.
+	WebhookAllowedNetworks []string `yaml:"webhook_allowed_networks"`
+}
+
+// TracingConfig exports the spans of the requests and actions.
+type TracingConfig struct {
+	//Exporter is none, otlp or file.
+	Exporter string `yaml:"exporter"`
+	//Endpoint is the base url of the OTLP/HTTP receiver, the spans are posted
+	//to its /v1/traces.
+	Endpoint    string `yaml:"endpoint"`
+	File        string `yaml:"file"`
+	ServiceName string `yaml:"service_name"`
+}
+
+// LoggingConfig sets how the server logs.
+type LoggingConfig struct {
+	//Format is json, one object per record, or text.
+	Format string `yaml:"format"`
+	//Level is debug, info, warn or error.
+	Level string `yaml:"level"`
+}
+
+// AuthConfig sets how the callers are authenticated.
+type AuthConfig struct {
+	//Disabled turns the authentication off, for local development only.
+	Disabled bool `yaml:"disabled"`
+	//BootstrapAPIKey is accepted as an API key so that the first keys can be issued.
+	BootstrapAPIKey string `yaml:"bootstrap_api_key"`
+	//JWKSURL enables bearer tokens signed by the keys it serves.
+	JWKSURL     string `yaml:"jwks_url"`
+	JWTIssuer   string `yaml:"jwt_issuer"`
+	JWTAudience string `yaml:"jwt_audience"`
+	//JWTTenantClaim is the claim of the tokens naming the tenant of the caller.
+	JWTTenantClaim string `yaml:"jwt_tenant_claim"`
+}
+
+// SCMConfig are the credentials of the git hosting services whose pushes
+// and pull requests are planned.
+type SCMConfig struct {
+	//WebhookSecret is shared with GitHub (HMAC key) and GitLab (secret token)
+	//for the repositories of the default tenant, WebhookSecrets by the other
+	//tenants. A webhook only plans the configurations of the tenant whose
+	//secret it carries.
+	WebhookSecret  string            `yaml:"webhook_secret"`
+	WebhookSecrets map[string]string `yaml:"webhook_secrets"`
+	GitHubToken    string            `yaml:"github_token"`
+	GitHubAPIURL   string            `yaml:"github_api_url"`
+	GitLabToken    string            `yaml:"gitlab_token"`
+	GitLabAPIURL   string            `yaml:"gitlab_api_url"`
+}
+
+// SandboxConfig limits the terraform runs, see sandbox.go. 0 is no limit.
+type SandboxConfig struct {
+	//Env are the variables passed from the server environment to terraform.
+	Env []string `yaml:"env"`
+	//CPUSeconds limits the CPU time of a terraform process.
+	CPUSeconds int `yaml:"cpu_seconds"`
+	//MemoryMB limits the memory of a run, in its cgroup or else as an rlimit.
+	MemoryMB int `yaml:"memory_mb"`
+	//CPUs limits the CPUs of a run in its cgroup.
+	CPUs float64 `yaml:"cpus"`
+	//Cgroup is a cgroup v2 directory delegated to the server, each run gets
+	//a child cgroup.
+	Cgroup string `yaml:"cgroup"`
+	//UID and GID, when set, are the unprivileged user terraform runs as.
+	UID int `yaml:"uid"`
+	GID int `yaml:"gid"`
+	//UIDs, when above 1, gives each run a user of its own from UID and GID
+	//on, so that a run can not read the workspace of another.
+	UIDs int `yaml:"uids"`
+}
+
+// DefaultConfig is the configuration of a server given no settings.
+func DefaultConfig() Config {
+	return Config{
+		Port: 9080,
+		Storage: StorageConfig{
+			Kind:     "mongo",
+			MongoURL: "localhost",
+			MongoDB:  "action",
+			SQLDSN:   "terraform-api.db",
+		},
+		Dirs: DirsConfig{SwaggerUI: "./swagger/swagger-ui"},
+		Timeouts: TimeoutsConfig{
+			HTTP:   60 * time.Second,
+			Action: 60 * time.Minute,
+		},
+		TLS: TLSConfig{ClientAuth: "optional", ReloadInterval: 30 * time.Second},
+		Notifications: NotificationsConfig{
+			SMTP: SMTPSettings{Port: "25", From: "terraform-provider-ibm-api@localhost"},
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			Endpoint:    "http://localhost:4318",
+			ServiceName: "terraform-provider-ibm-api",
+		},
+		Logging: LoggingConfig{Format: "json", Level: "info"},
+		Auth:    AuthConfig{JWTTenantClaim: "tenant"},
+		SCM: SCMConfig{
+			GitHubAPIURL: "https://api.github.com",
+			GitLabAPIURL: "https://gitlab.com/api/v4",
+		},
+		Sandbox: SandboxConfig{Env: strings.Split(defaultSandboxEnv, ",")},
+	}
+}
+
+// serverSetting ties a setting to its environment variable and flag.
+type serverSetting struct {
+	env, flag, usage string
+	field            func(c *Config) interface{}
+}
+
+var serverSettings = []serverSetting{
+	{"PORT", "p", "Port on which this server listens", func(c *Config) interface{} { return &c.Port }},
+	{"STORE", "store", "Store of configurations and actions, mongo, memory, sqlite or postgres", func(c *Config) interface{} { return &c.Storage.Kind }},
+	{"MONGO_URL", "mongo-url", "MongoDB connection string", func(c *Config) interface{} { return &c.Storage.MongoURL }},
+	{"MONGO_DB", "mongo-db", "MongoDB database", func(c *Config) interface{} { return &c.Storage.MongoDB }},
+	{"SQL_DSN", "sql-dsn", "SQLite file or PostgreSQL connection string of the sql stores", func(c *Config) interface{} { return &c.Storage.SQLDSN }},
+	{"MOUNT_DIR", "mount-dir", "Directory of the configurations, their state and logs", func(c *Config) interface{} { return &c.Dirs.Mount }},
+	{"SWAGGER_UI_DIR", "staticPath", "Path to folder with Swagger UI", func(c *Config) interface{} { return &c.Dirs.SwaggerUI }},
+	{"HTTP_TIMEOUT", "http-timeout", "Longest time a request may take", func(c *Config) interface{} { return &c.Timeouts.HTTP }},
+	{"ACTION_TIMEOUT", "action-timeout", "Longest time a terraform action may take", func(c *Config) interface{} { return &c.Timeouts.Action }},
+	{"ACTION_WORKERS", "action-workers", "Terraform actions run at once, 0 for no limit", func(c *Config) interface{} { return &c.Workers.Actions }},
+	{"TLS_CERT_FILE", "tls-cert", "Certificate file, serves https when set", func(c *Config) interface{} { return &c.TLS.CertFile }},
+	{"TLS_KEY_FILE", "tls-key", "Private key file of the certificate", func(c *Config) interface{} { return &c.TLS.KeyFile }},
+	{"TLS_CLIENT_CA_FILE", "tls-client-ca", "CA file of the client certificates", func(c *Config) interface{} { return &c.TLS.ClientCAFile }},
+	{"TLS_CLIENT_AUTH", "tls-client-auth", "optional or require client certificates", func(c *Config) interface{} { return &c.TLS.ClientAuth }},
+	{"TLS_RELOAD_INTERVAL", "tls-reload-interval", "How often the certificate files are checked for changes", func(c *Config) interface{} { return &c.TLS.ReloadInterval }},
+	{"SLACK_INCOMING_WEBHOOK", "slack-webhook", "Default slack incoming webhook", func(c *Config) interface{} { return &c.Notifications.SlackWebhook }},
+	{"SLACK_BOT_TOKEN", "", "", func(c *Config) interface{} { return &c.Notifications.SlackBotToken }},
+	{"SLACK_CHANNEL", "slack-channel", "Slack channel posted to with the bot token", func(c *Config) interface{} { return &c.Notifications.SlackChannel }},
+	{"WEBHOOK_ALLOWED_NETWORKS", "webhook-allowed-networks", "Comma separated private networks the webhooks may reach", func(c *Config) interface{} { return &c.Notifications.WebhookAllowedNetworks }},
+	{"SMTP_HOST", "smtp-host", "SMTP server of the email channels", func(c *Config) interface{} { return &c.Notifications.SMTP.Host }},
+	{"SMTP_PORT", "smtp-port", "SMTP port", func(c *Config) interface{} { return &c.Notifications.SMTP.Port }},
+	{"SMTP_USERNAME", "", "", func(c *Config) interface{} { return &c.Notifications.SMTP.Username }},
+	{"SMTP_PASSWORD", "", "", func(c *Config) interface{} { return &c.Notifications.SMTP.Password }},
+	{"SMTP_FROM", "smtp-from", "Sender of the emails", func(c *Config) interface{} { return &c.Notifications.SMTP.From }},
+	{"TRACING_EXPORTER", "tracing-exporter", "Exporter of the spans, none, otlp or file", func(c *Config) interface{} { return &c.Tracing.Exporter }},
+	{"OTEL_EXPORTER_OTLP_ENDPOINT", "otlp-endpoint", "Base url of the OTLP/HTTP receiver of the spans", func(c *Config) interface{} { return &c.Tracing.Endpoint }},
+	{"TRACING_FILE", "tracing-file", "File the spans are written to with the file exporter", func(c *Config) interface{} { return &c.Tracing.File }},
+	{"OTEL_SERVICE_NAME", "service-name", "Service name of the spans", func(c *Config) interface{} { return &c.Tracing.ServiceName }},
+	{"LOG_FORMAT", "log-format", "Format of the logs, json or text", func(c *Config) interface{} { return &c.Logging.Format }},
+	{"LOG_LEVEL", "log-level", "Lowest level logged, debug, info, warn or error", func(c *Config) interface{} { return &c.Logging.Level }},
+	{"AUTH_DISABLED", "auth-disabled", "Turn the authentication off, for local development only", func(c *Config) interface{} { return &c.Auth.Disabled }},
+	{"BOOTSTRAP_API_KEY", "", "", func(c *Config) interface{} { return &c.Auth.BootstrapAPIKey }},
+	{"JWKS_URL", "jwks-url", "JWKS url of the keys signing the bearer tokens", func(c *Config) interface{} { return &c.Auth.JWKSURL }},
+	{"JWT_ISSUER", "jwt-issuer", "Issuer the bearer tokens must have", func(c *Config) interface{} { return &c.Auth.JWTIssuer }},
+	{"JWT_AUDIENCE", "jwt-audience", "Audience the bearer tokens must have", func(c *Config) interface{} { return &c.Auth.JWTAudience }},
+	{"JWT_TENANT_CLAIM", "jwt-tenant-claim", "Claim of the bearer tokens naming the tenant", func(c *Config) interface{} { return &c.Auth.JWTTenantClaim }},
+	{"GIT_WEBHOOK_SECRET", "", "", func(c *Config) interface{} { return &c.SCM.WebhookSecret }},
+	{"GITHUB_TOKEN", "", "", func(c *Config) interface{} { return &c.SCM.GitHubToken }},
+	{"GITHUB_API_URL", "github-api-url", "GitHub API, for GitHub Enterprise", func(c *Config) interface{} { return &c.SCM.GitHubAPIURL }},
+	{"GITLAB_TOKEN", "", "", func(c *Config) interface{} { return &c.SCM.GitLabToken }},
+	{"GITLAB_API_URL", "gitlab-api-url", "GitLab API, for self-hosted GitLab", func(c *Config) interface{} { return &c.SCM.GitLabAPIURL }},
+	{"SANDBOX_ENV", "sandbox-env", "Comma separated variables passed to terraform", func(c *Config) interface{} { return &c.Sandbox.Env }},
+	{"SANDBOX_CPU_SECONDS", "sandbox-cpu-seconds", "CPU time of a terraform process, 0 for no limit", func(c *Config) interface{} { return &c.Sandbox.CPUSeconds }},
+	{"SANDBOX_MEMORY_MB", "sandbox-memory-mb", "Memory of a terraform run, 0 for no limit", func(c *Config) interface{} { return &c.Sandbox.MemoryMB }},
+	{"SANDBOX_CPUS", "sandbox-cpus", "CPUs of a terraform run in its cgroup, 0 for no limit", func(c *Config) interface{} { return &c.Sandbox.CPUs }},
+	{"SANDBOX_CGROUP", "sandbox-cgroup", "cgroup v2 directory delegated to the server", func(c *Config) interface{} { return &c.Sandbox.Cgroup }},
+	{"SANDBOX_UID", "sandbox-uid", "User terraform runs as, the server runs as root", func(c *Config) interface{} { return &c.Sandbox.UID }},
+	{"SANDBOX_GID", "sandbox-gid", "Group terraform runs as", func(c *Config) interface{} { return &c.Sandbox.GID }},
+	{"SANDBOX_UIDS", "sandbox-uids", "Users from sandbox-uid on, each run gets one of its own", func(c *Config) interface{} { return &c.Sandbox.UIDs }},
+}
+
+// setConfigValue parses s into the setting field.
+func setConfigValue(field interface{}, s string) error {
+	switch p := field.(type) {
+	case *string:
+		*p = s
+	case *int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", s)
+		}
+		*p = n
+	case *time.Duration:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration", s)
+		}
+		*p = d
+	case *float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", s)
+		}
+		*p = f
+	case *bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", s)
+		}
+		*p = b
+	case *[]string:
+		*p = nil
+		for _, v := range strings.Split(s, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				*p = append(*p, v)
+			}
+		}
+	}
+	return nil
+}
+
+// ConfigFlags are the flags of the settings, see RegisterConfigFlags.
+type ConfigFlags struct {
+	fs     *flag.FlagSet
+	values map[string]*string
+}
+
+// RegisterConfigFlags registers a flag for the settings that have one. Only
+// the flags given on the command line override the settings.
+// Secrets have no flag, they would show in the process list.
+func RegisterConfigFlags(fs *flag.FlagSet) *ConfigFlags {
+	f := &ConfigFlags{fs: fs, values: make(map[string]*string)}
+	for _, s := range serverSettings {
+		if s.flag != "" {
+			f.values[s.flag] = fs.String(s.flag, "", s.usage+" ("+s.env+")")
+		}
+	}
+	return f
+}
+
+// LoadConfig reads the configuration from the yaml file, when one is given,
+// the environment and the flags. It fails on a setting that can not be
+// parsed or is invalid.
+func LoadConfig(file string, flags *ConfigFlags) (Config, error) {
+	c := DefaultConfig()
+	if file != "" {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return c, fmt.Errorf("failed to read the configuration file: %v", err)
+		}
+		err = yaml.UnmarshalStrict(b, &c)
+		if err != nil {
+			return c, fmt.Errorf("invalid configuration file %s: %v", file, err)
+		}
+	}
+
+	var errs []string
+	for _, s := range serverSettings {
+		if v, ok := os.LookupEnv(s.env); ok && v != "" {
+			if err := setConfigValue(s.field(&c), v); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", s.env, err))
+			}
+		}
+	}
+	if flags != nil {
+		set := map[string]bool{}
+		flags.fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		for _, s := range serverSettings {
+			if set[s.flag] {
+				if err := setConfigValue(s.field(&c), *flags.values[s.flag]); err != nil {
+					errs = append(errs, fmt.Sprintf("-%s: %v", s.flag, err))
+				}
+			}
+		}
+	}
+	errs = append(errs, c.validate()...)
+	if len(errs) > 0 {
+		return c, fmt.Errorf("invalid configuration:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return c, nil
+}
+
+// validate returns the problems of the configuration.
+func (c Config) validate() []string {
+	var errs []string
+	if c.Port < 1 || c.Port > 65535 {
+		errs = append(errs, fmt.Sprintf("port %d is not a tcp port", c.Port))
+	}
+	switch c.Storage.Kind {
+	case "mongo":
+		if c.Storage.MongoURL == "" || c.Storage.MongoDB == "" {
+			errs = append(errs, "storage.mongo_url and storage.mongo_db are required with the mongo store")
+		}
+	case "sqlite", "postgres":
+		if c.Storage.SQLDSN == "" {
+			errs = append(errs, "storage.sql_dsn is required with the "+c.Storage.Kind+" store")
+		}
+	case "memory":
+	default:
+		errs = append(errs, fmt.Sprintf("storage.kind %q must be mongo, memory, sqlite or postgres", c.Storage.Kind))
+	}
+
+	if c.Dirs.Mount == "" {
+		errs = append(errs, "dirs.mount (MOUNT_DIR) is required, it holds the configurations")
+	} else if info, err := os.Stat(c.Dirs.Mount); err != nil || !info.IsDir() {
+		errs = append(errs, fmt.Sprintf("dirs.mount %s is not a directory", c.Dirs.Mount))
+	}
+
+	if c.Timeouts.HTTP <= 0 {
+		errs = append(errs, "timeouts.http must be positive")
+	}
+	for route, d := range c.Timeouts.Routes {
+		if d < 0 {
+			errs = append(errs, fmt.Sprintf("timeouts.routes %s must not be negative", route))
+		}
+	}
+	if c.Timeouts.Action <= 0 {
+		errs = append(errs, "timeouts.action must be positive")
+	}
+	if c.Workers.Actions < 0 {
+		errs = append(errs, "workers.actions must not be negative")
+	}
+
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		errs = append(errs, "tls.cert_file and tls.key_file must be set together")
+	}
+	if c.TLS.ClientCAFile != "" && c.TLS.CertFile == "" {
+		errs = append(errs, "tls.client_ca_file needs tls.cert_file")
+	}
+	for _, f := range []string{c.TLS.CertFile, c.TLS.KeyFile, c.TLS.ClientCAFile} {
+		if _, err := os.Stat(f); f != "" && err != nil {
+			errs = append(errs, fmt.Sprintf("tls file %s: %v", f, err))
+		}
+	}
+	if c.TLS.ClientAuth != "optional" && c.TLS.ClientAuth != "require" {
+		errs = append(errs, fmt.Sprintf("tls.client_auth %q must be optional or require", c.TLS.ClientAuth))
+	}
+	if len(c.TLS.ClientIdentities) > 0 && c.TLS.ClientCAFile == "" {
+		errs = append(errs, "tls.client_identities needs tls.client_ca_file")
+	}
+	for certSubject, id := range c.TLS.ClientIdentities {
+		if id.Subject == "" {
+			errs = append(errs, fmt.Sprintf("tls.client_identities %s needs a subject", certSubject))
+		}
+		if id.Tenant != "" {
+			if err := validateTenant(id.Tenant); err != nil {
+				errs = append(errs, fmt.Sprintf("tls.client_identities %s: %v", certSubject, err))
+			}
+		}
+	}
+	if c.TLS.ReloadInterval <= 0 {
+		errs = append(errs, "tls.reload_interval must be positive")
+	}
+
+	hasChannel := c.Notifications.SlackChannel != "" || len(c.Notifications.SlackChannels) > 0
+	if c.Notifications.SlackBotToken != "" && !hasChannel {
+		errs = append(errs, "notifications.slack_bot_token needs notifications.slack_channel or notifications.slack_channels")
+	}
+	if c.Notifications.SlackBotToken == "" && hasChannel {
+		errs = append(errs, "notifications.slack_channel and notifications.slack_channels need notifications.slack_bot_token")
+	}
+	for tenant, channel := range c.Notifications.SlackChannels {
+		if err := validateTenant(tenant); err != nil {
+			errs = append(errs, fmt.Sprintf("notifications.slack_channels %s: %v", tenant, err))
+		}
+		if channel == "" {
+			errs = append(errs, fmt.Sprintf("notifications.slack_channels %s has no channel", tenant))
+		}
+	}
+	for _, cidr := range c.Notifications.WebhookAllowedNetworks {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			errs = append(errs, fmt.Sprintf("notifications.webhook_allowed_networks %q is not a CIDR", cidr))
+		}
+	}
+	if _, err := strconv.Atoi(c.Notifications.SMTP.Port); err != nil {
+		errs = append(errs, fmt.Sprintf("notifications.smtp.port %q is not a number", c.Notifications.SMTP.Port))
+	}
+
+	switch c.Tracing.Exporter {
+	case "none":
+	case "otlp":
+		if u, err := url.Parse(c.Tracing.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Sprintf("tracing.endpoint %q is not a url", c.Tracing.Endpoint))
+		}
+	case "file":
+		if c.Tracing.File == "" {
+			errs = append(errs, "tracing.file is required with the file exporter")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("tracing.exporter %q must be none, otlp or file", c.Tracing.Exporter))
+	}
+
+	if c.Logging.Format != "json" && c.Logging.Format != "text" {
+		errs = append(errs, fmt.Sprintf("logging.format %q must be json or text", c.Logging.Format))
+	}
+	if _, err := parseLevel(c.Logging.Level); err != nil {
+		errs = append(errs, "logging.level: "+err.Error())
+	}
+
+	if c.Auth.JWKSURL != "" {
+		if u, err := url.Parse(c.Auth.JWKSURL); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Sprintf("auth.jwks_url %q is not a url", c.Auth.JWKSURL))
+		}
+		if c.Auth.JWTTenantClaim == "" {
+			errs = append(errs, "auth.jwt_tenant_claim is required with auth.jwks_url")
+		}
+	}
+	for _, api := range []struct{ name, url string }{
+		{"scm.github_api_url", c.SCM.GitHubAPIURL},
+		{"scm.gitlab_api_url", c.SCM.GitLabAPIURL},
+	} {
+		if u, err := url.Parse(api.url); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Sprintf("%s %q is not a url", api.name, api.url))
+		}
+	}
+	secretTenants := map[string]string{}
+	if c.SCM.WebhookSecret != "" {
+		secretTenants[c.SCM.WebhookSecret] = defaultTenant
+	}
+	for tenant, secret := range c.SCM.WebhookSecrets {
+		if err := validateTenant(tenant); err != nil {
+			errs = append(errs, fmt.Sprintf("scm.webhook_secrets %s: %v", tenant, err))
+		}
+		if tenant == defaultTenant && c.SCM.WebhookSecret != "" {
+			errs = append(errs, "scm.webhook_secrets has the default tenant, whose secret is scm.webhook_secret")
+		}
+		if secret == "" {
+			errs = append(errs, fmt.Sprintf("scm.webhook_secrets %s has no secret", tenant))
+		} else if other, ok := secretTenants[secret]; ok && other != tenant {
+			// A webhook signed with a shared secret would plan the configurations of both.
+			errs = append(errs, fmt.Sprintf("scm.webhook_secrets %s has the secret of %s", tenant, other))
+		}
+		secretTenants[secret] = tenant
+	}
+
+	for _, n := range []struct {
+		name  string
+		value int
+	}{
+		{"sandbox.cpu_seconds", c.Sandbox.CPUSeconds},
+		{"sandbox.memory_mb", c.Sandbox.MemoryMB},
+		{"sandbox.uid", c.Sandbox.UID},
+		{"sandbox.gid", c.Sandbox.GID},
+		{"sandbox.uids", c.Sandbox.UIDs},
+	} {
+		if n.value < 0 {
+			errs = append(errs, n.name+" must not be negative")
+		}
+	}
+	if c.Sandbox.CPUs < 0 {
+		errs = append(errs, "sandbox.cpus must not be negative")
+	}
+	if c.Sandbox.UID > 0 && c.Sandbox.GID == 0 {
+		errs = append(errs, "sandbox.gid is required with sandbox.uid")
+	}
+	if c.Sandbox.UIDs > 1 && c.Sandbox.UID == 0 {
+		errs = append(errs, "sandbox.uid is required with sandbox.uids")
+	}
+	return errs
+}
+
+// Configure applies the configuration to the server and creates the
+// directories it needs.
+func Configure(c Config) error {
+	if err := SetLogging(c.Logging.Format, c.Logging.Level); err != nil {
+		return err
+	}
+
+	currentDir = filepath.Clean(c.Dirs.Mount)
+	logDir = currentDir + "/log/"
+	stateDir = currentDir + "/state"
+	sandboxWorkDir = filepath.Join(currentDir, "work")
+	// The logs and state hold secrets, only the server reads them.
+	for _, dir := range []string{logDir, stateDir} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+		if err := os.Chmod(dir, 0700); err != nil {
+			return err
+		}
+	}
+	if err := moveDefaultConfigs(); err != nil {
+		return err
+	}
+
+	planTimeOut = c.Timeouts.Action
+	setActionWorkers(c.Workers.Actions)
+
+	clientIdentities = c.TLS.ClientIdentities
+
+	DefaultIncomingWebHook = c.Notifications.SlackWebhook
+	slackBotToken = c.Notifications.SlackBotToken
+	slackChannel = c.Notifications.SlackChannel
+	slackChannels = c.Notifications.SlackChannels
+	smtpSettings = c.Notifications.SMTP
+	webhookAllowedNetworks = nil
+	for _, cidr := range c.Notifications.WebhookAllowedNetworks {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return err
+		}
+		webhookAllowedNetworks = append(webhookAllowedNetworks, network)
+	}
+
+	authDisabled = c.Auth.Disabled
+	bootstrapAPIKey = c.Auth.BootstrapAPIKey
+	tokenValidator = nil
+	if c.Auth.JWKSURL != "" {
+		tokenValidator = newJWTValidator(c.Auth.JWKSURL, c.Auth.JWTIssuer, c.Auth.JWTAudience, c.Auth.JWTTenantClaim)
+	}
+
+	gitWebhookSecrets = map[string]string{}
+	for tenant, secret := range c.SCM.WebhookSecrets {
+		gitWebhookSecrets[tenant] = secret
+	}
+	if c.SCM.WebhookSecret != "" {
+		gitWebhookSecrets[defaultTenant] = c.SCM.WebhookSecret
+	}
+	githubToken = c.SCM.GitHubToken
+	scmClients["github"] = &githubClient{baseURL: c.SCM.GitHubAPIURL, token: c.SCM.GitHubToken}
+	scmClients["gitlab"] = &gitlabClient{baseURL: c.SCM.GitLabAPIURL, token: c.SCM.GitLabToken}
+
+	sandboxEnvPassthrough = c.Sandbox.Env
+	sandboxCPUSeconds = c.Sandbox.CPUSeconds
+	sandboxMemoryMB = c.Sandbox.MemoryMB
+	sandboxCPUs = c.Sandbox.CPUs
+	sandboxCgroup = c.Sandbox.Cgroup
+	setSandboxUsers(c.Sandbox.UID, c.Sandbox.GID, c.Sandbox.UIDs)
+	if sandboxUID == 0 {
+		// Terraform would read the variables and state of every
+		// configuration, and as root anything else.
+		if os.Geteuid() == 0 {
+			return fmt.Errorf("refusing to run terraform as root, set sandbox.uid and sandbox.gid")
+		}
+		Log.Warn("No sandbox user is set, terraform runs as the server user and can read every configuration. Set sandbox.uid and sandbox.gid")
+	}
+
+	return setTracing(c.Tracing)
+}
@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronErrors(t *testing.T) {
+	for _, tt := range []struct{ expr, timeZone string }{
+		{"", ""},
+		{"* * * *", ""},
+		{"* * * * * *", ""},
+		{"60 * * * *", ""},
+		{"* 24 * * *", ""},
+		{"* * 0 * *", ""},
+		{"* * 32 * *", ""},
+		{"* * * 13 *", ""},
+		{"* * * * 8", ""},
+		{"30-10 * * * *", ""},
+		{"fri-mon * * * *", ""},
+		{"* * * * fri-mon", ""},
+		{"*/0 * * * *", ""},
+		{"*/x * * * *", ""},
+		{"a * * * *", ""},
+		{"1,,2 * * * *", ""},
+		{"* * * foo *", ""},
+		{"@reboot", ""},
+		{"0 0 * * *", "Mars/Olympus_Mons"},
+	} {
+		if _, err := parseCron(tt.expr, tt.timeZone); err == nil {
+			t.Errorf("parseCron(%q, %q) accepted", tt.expr, tt.timeZone)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	utc := func(s string) time.Time {
+		v, err := time.Parse("2006-01-02 15:04:05", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	tests := []struct {
+		name, expr string
+		from       string
+		want       string // zero for never
+	}{
+		{"every minute", "* * * * *", "2024-01-01 10:07:30", "2024-01-01 10:08:00"},
+		{"strictly after", "30 2 * * *", "2024-01-01 02:30:00", "2024-01-02 02:30:00"},
+		{"same day", "30 2 * * *", "2024-01-01 02:29:59", "2024-01-01 02:30:00"},
+		{"minute step", "*/15 * * * *", "2024-01-01 10:07:00", "2024-01-01 10:15:00"},
+		{"step from a value", "5/20 * * * *", "2024-01-01 10:26:00", "2024-01-01 10:45:00"},
+		{"range with a step", "0 8-18/5 * * *", "2024-01-01 13:01:00", "2024-01-01 18:00:00"},
+		{"range with a step wraps to the next day", "0 8-18/5 * * *", "2024-01-01 18:00:00", "2024-01-02 08:00:00"},
+		{"list", "0 6,18 * * *", "2024-01-01 06:00:00", "2024-01-01 18:00:00"},
+		{"end of the hour", "59 * * * *", "2024-01-01 23:59:00", "2024-01-02 00:59:00"},
+		{"weekdays from a friday", "0 19 * * 1-5", "2024-01-05 19:00:00", "2024-01-08 19:00:00"},
+		{"weekday names", "0 19 * * mon-fri", "2024-01-06 12:00:00", "2024-01-08 19:00:00"},
+		{"sunday as 7", "0 0 * * 7", "2024-01-01 00:00:00", "2024-01-07 00:00:00"},
+		{"sunday as 0", "0 0 * * 0", "2024-01-01 00:00:00", "2024-01-07 00:00:00"},
+		{"month names", "0 9 * jan,mar mon", "2024-01-30 00:00:00", "2024-03-04 09:00:00"},
+		{"day of month", "0 0 13 * *", "2024-01-01 00:00:00", "2024-01-13 00:00:00"},
+		{"day of month or day of week", "0 0 13 * fri", "2024-01-01 00:00:00", "2024-01-05 00:00:00"},
+		{"day of month or day of week, the day", "0 0 13 * fri", "2024-01-12 00:00:00", "2024-01-13 00:00:00"},
+		// A stepped day of month is a *, both day fields have to match.
+		{"day of week with a stepped day of month", "0 0 */10 * mon", "2024-01-01 00:00:00", "2024-03-11 00:00:00"},
+		{"31st skips short months", "0 0 31 * *", "2024-04-01 00:00:00", "2024-05-31 00:00:00"},
+		{"end of the year", "@yearly", "2024-06-01 00:00:00", "2025-01-01 00:00:00"},
+		{"hourly", "@hourly", "2024-01-01 10:00:00", "2024-01-01 11:00:00"},
+		{"leap day", "0 12 29 2 *", "2024-03-01 00:00:00", "2028-02-29 12:00:00"},
+		{"never", "0 12 30 2 *", "2024-01-01 00:00:00", ""},
+		{"31 april never", "0 0 31 4 *", "2024-01-01 00:00:00", ""},
+	}
+	for _, tt := range tests {
+		c, err := parseCron(tt.expr, "")
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		got := c.Next(utc(tt.from))
+		var want time.Time
+		if tt.want != "" {
+			want = utc(tt.want)
+		}
+		if !got.Equal(want) {
+			t.Errorf("%s: %q Next(%s) = %v, want %v", tt.name, tt.expr, tt.from, got, want)
+		}
+	}
+}
+
+func TestCronNextTimeZone(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	//at is a time at a UTC offset in hours.
+	at := func(y int, m time.Month, d, h, min int, offset int) time.Time {
+		return time.Date(y, m, d, h, min, 0, 0, time.FixedZone("", offset*3600))
+	}
+
+	tests := []struct {
+		name, expr string
+		from, want time.Time
+	}{
+		{"evaluated in the time zone", "0 19 * * 1-5", at(2024, 1, 6, 0, 30, 0), at(2024, 1, 8, 19, 0, -5)},
+		{"before the clocks go forward", "30 1 * * *", at(2024, 3, 9, 12, 0, -5), at(2024, 3, 10, 1, 30, -5)},
+		// 02:30 does not exist on 10 March 2024, it runs an hour later.
+		{"skipped time", "30 2 * * *", at(2024, 3, 9, 12, 0, -5), at(2024, 3, 10, 3, 30, -4)},
+		{"after a skipped time", "30 2 * * *", at(2024, 3, 10, 3, 30, -4), at(2024, 3, 11, 2, 30, -4)},
+		{"hourly over the skipped hour", "0 * * * *", at(2024, 3, 10, 1, 0, -5), at(2024, 3, 10, 3, 0, -4)},
+		{"daily across the change", "0 19 * * *", at(2024, 3, 9, 19, 0, -5), at(2024, 3, 10, 19, 0, -4)},
+		// 01:30 happens twice on 3 November 2024, it runs once.
+		{"repeated time", "30 1 * * *", at(2024, 11, 3, 0, 0, -4), at(2024, 11, 3, 1, 30, -4)},
+		{"after a repeated time", "30 1 * * *", at(2024, 11, 3, 1, 30, -4), at(2024, 11, 4, 1, 30, -5)},
+		// The repeated hour ran already.
+		{"within the repeated hour", "*/15 * * * *", at(2024, 11, 3, 1, 10, -5), at(2024, 11, 3, 2, 0, -5)},
+		{"end of the repeated hour", "*/15 * * * *", at(2024, 11, 3, 1, 45, -4), at(2024, 11, 3, 2, 0, -5)},
+	}
+	for _, tt := range tests {
+		c, err := parseCron(tt.expr, "America/New_York")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := c.Next(tt.from)
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: %q Next(%s) = %s, want %s", tt.name, tt.expr, tt.from.In(ny), got.In(ny), tt.want.In(ny))
+		}
+	}
+
+	// Every run of a daily schedule is a day apart on the wall clock, the
+	// scheduler computing each run from the one before.
+	c, _ := parseCron("30 1 * * *", "America/New_York")
+	run := at(2024, 10, 30, 1, 30, -4)
+	for i := 0; i < 10; i++ {
+		next := c.Next(run)
+		if y, m, d := run.In(ny).AddDate(0, 0, 1).Date(); next.In(ny).Day() != d || next.In(ny).Month() != m || next.In(ny).Year() != y ||
+			next.In(ny).Hour() != 1 || next.In(ny).Minute() != 30 {
+			t.Errorf("after %s got %s", run.In(ny), next.In(ny))
+		}
+		run = next
+	}
+}
@@ -0,0 +1,259 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// driftPollInterval is how often the scheduler looks for configurations that are due a drift check.
+var driftPollInterval = time.Minute
+
+// defaultDriftInterval is used when a configuration opts in without an interval.
+var defaultDriftInterval = 24 * time.Hour
+
+// The statuses of the notices about drift checks.
+const (
+	driftDetected = "Drift detected"
+	driftCleared  = "Drift cleared"
+)
+
+// DriftRequest -
+type DriftRequest struct {
+	Enabled  bool   `json:"enabled" description:"Enable or disable drift detection for the configuration"`
+	Interval string `json:"interval,omitempty" description:"How often to check for drift, e.g. 30m or 6h. Defaults to 24h"`
+	Webhook  string `json:"slack_webhook_url,omitempty" description:"Slack webhook notified when drift appears or clears"`
+}
+
+// DriftStatus -
+type DriftStatus struct {
+	ConfigName   string    `json:"id" description:"Name of the configuration"`
+	Enabled      bool      `json:"enabled"`
+	Interval     string    `json:"interval"`
+	Webhook      string    `json:"-"`
+	LogURL       string    `json:"-"`
+	Drifted      bool      `json:"drifted" description:"Whether the last check found resources changed outside terraform"`
+	Resources    []string  `json:"resources,omitempty" description:"Addresses of the drifted resources"`
+	LastCheck    time.Time `json:"last_check,omitempty"`
+	LastActionID string    `json:"last_action_id,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// DriftConfigHandler handles request to opt a configuration in or out of drift detection.
+func DriftConfigHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session := s.Copy()
+		defer session.Close()
+
+		repoName := configOf(r)
+
+		if _, err := os.Stat(configDir(repoName)); err != nil {
+			http.Error(w, "There is no config repo for this request.", 404)
+			return
+		}
+
+		b, err := ioutil.ReadAll(r.Body)
+		defer r.Body.Close()
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+
+		var msg DriftRequest
+		err = json.Unmarshal(b, &msg)
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+
+		if msg.Interval == "" {
+			msg.Interval = defaultDriftInterval.String()
+		}
+		interval, err := time.ParseDuration(msg.Interval)
+		if err != nil || interval < driftPollInterval {
+			http.Error(w, fmt.Sprintf("Invalid interval %q, it must be a duration of at least %s", msg.Interval, driftPollInterval), 400)
+			return
+		}
+
+		c := session.DB(dbName).C("driftStatus")
+		_, err = c.Upsert(bson.M{"configname": repoName}, bson.M{"$set": bson.M{
+			"enabled":  msg.Enabled,
+			"interval": msg.Interval,
+			"webhook":  msg.Webhook,
+			"logurl":   "http://" + r.Host + "/v1/configuration/" + displayName(repoName) + "/drift",
+		}})
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+
+		var response DriftStatus
+		err = c.Find(bson.M{"configname": repoName}).One(&response)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		response.ConfigName = repoName
+
+		output, err := json.MarshalIndent(response, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		w.Header().Set("content-type", "application/json")
+		w.Write(output)
+	}
+}
+
+// DriftStatusHandler handles request to get the drift state of a configuration.
+func DriftStatusHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session := s.Copy()
+		defer session.Close()
+
+		repoName := configOf(r)
+
+		var response DriftStatus
+		c := session.DB(dbName).C("driftStatus")
+		err := c.Find(bson.M{"configname": repoName}).One(&response)
+		if err == mgo.ErrNotFound {
+			http.Error(w, "Drift detection is not configured for this configuration.", 404)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		response.ConfigName = repoName
+
+		output, err := json.MarshalIndent(response, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		w.Header().Set("content-type", "application/json")
+		w.Write(output)
+	}
+}
+
+// StartDriftScheduler periodically runs a refresh-only plan for every configuration
+// that opted in to drift detection.
+func StartDriftScheduler(s *mgo.Session) {
+	go func() {
+		ticker := time.NewTicker(driftPollInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			checkDueDrift(s)
+		}
+	}()
+}
+
+// checkDueDrift starts a drift check for every configuration that is due one.
+// The checks run as actions, a slow refresh does not hold back the others.
+func checkDueDrift(s *mgo.Session) {
+	session := s.Copy()
+	defer session.Close()
+
+	var due []DriftStatus
+	c := session.DB(dbName).C("driftStatus")
+	err := c.Find(bson.M{"enabled": true}).All(&due)
+	if err != nil {
+		Log.Error("Failed to load the drift detection settings", "error", err)
+		return
+	}
+
+	for _, d := range due {
+		if _, err := os.Stat(configDir(d.ConfigName)); err != nil {
+			Log.Info("Removing the drift detection, there is no config repo", "config", d.ConfigName)
+			c.Remove(bson.M{"configname": d.ConfigName})
+			continue
+		}
+		interval, err := time.ParseDuration(d.Interval)
+		if err != nil {
+			interval = defaultDriftInterval
+		}
+		if time.Since(d.LastCheck) < interval {
+			continue
+		}
+		// The check is due again an interval after it started, not after
+		// it finished, the next tick does not start it a second time.
+		err = c.Update(bson.M{"configname": d.ConfigName}, bson.M{"$set": bson.M{"lastcheck": time.Now()}})
+		if err != nil {
+			Log.Error("Failed to update the drift status", "config", d.ConfigName, "error", err)
+			continue
+		}
+		runDriftCheck(s, d)
+	}
+}
+
+// runDriftCheck starts a refresh-only plan as a "drift" action and stores its
+// outcome. It notifies when the drift state flips or the check starts failing.
+func runDriftCheck(s *mgo.Session, d DriftStatus) ActionResponse {
+	var resources []string
+	var checkErr error
+	runner := func(ctx context.Context, confDir, repoName, randomID string) error {
+		Log.InfoContext(ctx, "Checking drift")
+		pullRepo(ctx, repoName)
+		checkErr = inWorkspace(confDir, repoName, randomID, func(ws *workspace) (err error) {
+			resources, err = TerraformDriftPlan(ws.Dir, ws.StateDir, repoName, &planTimeOut, randomID)
+			return err
+		})
+		return checkErr
+	}
+
+	filter := func(notice *ActionNotice) bool {
+		return driftNotice(d, resources, notice)
+	}
+
+	done := func(result ActionResponse) {
+		session := s.Copy()
+		defer session.Close()
+
+		update := bson.M{"lastactionid": result.ActionID}
+		if checkErr != nil {
+			update["error"] = checkErr.Error()
+		} else {
+			update["error"] = ""
+			update["drifted"] = len(resources) > 0
+			update["resources"] = resources
+		}
+		c := session.DB(dbName).C("driftStatus")
+		err := c.Update(bson.M{"configname": d.ConfigName}, bson.M{"$set": update})
+		if err != nil {
+			Log.ErrorContext(actionContext(result.ActionID), "Failed to update the drift status", "error", err)
+		}
+	}
+
+	return startActionWith(context.Background(), s, d.ConfigName, "drift", d.LogURL, d.Webhook, runner, filter, done)
+}
+
+// driftNotice turns the notice about a drift check of the configuration, last
+// checked as d, into a drift notice. It returns false unless the drift state
+// flips or the check starts failing.
+func driftNotice(d DriftStatus, resources []string, notice *ActionNotice) bool {
+	switch notice.Status {
+	case "In-Progress":
+		return false
+	case "Failed":
+		return d.Error == ""
+	}
+	drifted := len(resources) > 0
+	if drifted == d.Drifted {
+		return false
+	}
+	notice.Status = driftCleared
+	notice.Summary = ""
+	if drifted {
+		notice.Status = driftDetected
+		notice.Summary = fmt.Sprintf("%d resource(s) changed outside terraform: %s", len(resources), strings.Join(resources, ", "))
+	}
+	return true
+}
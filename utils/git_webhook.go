@@ -0,0 +1,484 @@
+package utils
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/gorilla/mux"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// gitWebhookSecrets are shared with GitHub (HMAC key) and GitLab (secret
+// token), by tenant, set by Configure.
+var gitWebhookSecrets map[string]string
+
+// GitTriggerRequest -
+type GitTriggerRequest struct {
+	Provider     string `json:"provider,required" description:"github or gitlab"`
+	Repository   string `json:"repository,omitempty" description:"Repository url. Defaults to the origin of the configuration repo"`
+	Branch       string `json:"branch,omitempty" description:"Branch whose pushes trigger a plan. Defaults to master"`
+	PullRequests bool   `json:"pull_requests,omitempty" description:"Also plan pull/merge requests targeting the branch"`
+	Webhook      string `json:"slack_webhook_url,omitempty" description:"Slack webhook notified about the triggered plans"`
+}
+
+// GitTrigger -
+type GitTrigger struct {
+	ConfigName   string `json:"id" description:"Name of the configuration"`
+	Provider     string `json:"provider"`
+	Repository   string `json:"repository"`
+	Branch       string `json:"branch"`
+	PullRequests bool   `json:"pull_requests"`
+	Webhook      string `json:"-"`
+}
+
+// gitEvent is the part of a push or pull request payload needed to plan it.
+type gitEvent struct {
+	Provider string
+	RepoURLs []string
+	RepoID   string // owner/name on GitHub, project path on GitLab
+	Branch   string // pushed branch or pull request target branch
+	SHA      string
+	Number   int    // pull or merge request number, 0 for a push
+	FetchRef string // ref to fetch the pull request head from
+	Fork     bool   // the pull request comes from another repository
+}
+
+// GitTriggerHandler handles request to plan the configuration on git pushes and pull requests.
+func GitTriggerHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session := s.Copy()
+		defer session.Close()
+
+		repoName := configOf(r)
+
+		confDir := configDir(repoName)
+		if _, err := os.Stat(confDir); err != nil {
+			http.Error(w, "There is no config repo for this request.", 404)
+			return
+		}
+
+		b, err := ioutil.ReadAll(r.Body)
+		defer r.Body.Close()
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+
+		var msg GitTriggerRequest
+		err = json.Unmarshal(b, &msg)
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		if _, ok := scmClients[msg.Provider]; !ok {
+			http.Error(w, fmt.Sprintf("Invalid provider %q, it must be github or gitlab", msg.Provider), 400)
+			return
+		}
+		if msg.Repository == "" {
+			cmd := exec.Command("git", "config", "--get", "remote.origin.url")
+			cmd.Dir = confDir
+			out, err := cmd.Output()
+			if err != nil {
+				http.Error(w, "Could not find the origin of the config repo, provide the repository.", 400)
+				return
+			}
+			msg.Repository = strings.TrimSpace(string(out))
+		}
+		if msg.Branch == "" {
+			msg.Branch = "master"
+		}
+
+		trigger := GitTrigger{
+			ConfigName:   repoName,
+			Provider:     msg.Provider,
+			Repository:   normalizeRepoURL(msg.Repository),
+			Branch:       msg.Branch,
+			PullRequests: msg.PullRequests,
+			Webhook:      msg.Webhook,
+		}
+
+		c := session.DB(dbName).C("gitTriggers")
+		_, err = c.Upsert(bson.M{"configname": repoName}, trigger)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+
+		output, err := json.MarshalIndent(trigger, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		w.Header().Set("content-type", "application/json")
+		w.Write(output)
+	}
+}
+
+// GitTriggerDeleteHandler handles request to stop planning the configuration on git events.
+func GitTriggerDeleteHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session := s.Copy()
+		defer session.Close()
+
+		repoName := configOf(r)
+
+		c := session.DB(dbName).C("gitTriggers")
+		err := c.Remove(bson.M{"configname": repoName})
+		if err == mgo.ErrNotFound {
+			http.Error(w, "There is no git trigger for this configuration.", 404)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+	}
+}
+
+// GitWebhookHandler handles push and pull request events sent by GitHub or GitLab.
+func GitWebhookHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		provider := vars["provider"]
+
+		b, err := ioutil.ReadAll(r.Body)
+		defer r.Body.Close()
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+
+		// The tenants whose secret the webhook carries, only their
+		// configurations are planned.
+		var event *gitEvent
+		var tenants map[string]bool
+		switch provider {
+		case "github":
+			tenants = gitWebhookTenants(func(secret string) bool {
+				return verifyGitHubSignature(secret, r.Header.Get("X-Hub-Signature-256"), b)
+			})
+			if len(tenants) == 0 {
+				http.Error(w, "Invalid webhook signature.", 401)
+				return
+			}
+			event, err = parseGitHubEvent(r.Header.Get("X-GitHub-Event"), b)
+		case "gitlab":
+			tenants = gitWebhookTenants(func(secret string) bool {
+				return verifyGitLabToken(secret, r.Header.Get("X-Gitlab-Token"))
+			})
+			if len(tenants) == 0 {
+				http.Error(w, "Invalid webhook token.", 401)
+				return
+			}
+			event, err = parseGitLabEvent(r.Header.Get("X-Gitlab-Event"), b)
+		default:
+			http.Error(w, "Unknown webhook provider.", 404)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+
+		// The head of a pull request from a fork is code of anyone, it is not
+		// run with the credentials of the server.
+		if event != nil && event.Fork {
+			Log.WarnContext(r.Context(), "Not planning a pull request from a fork", "repository", event.RepoID, "pull_request", event.Number)
+			event = nil
+		}
+
+		started := []ActionResponse{}
+		if event != nil {
+			session := s.Copy()
+			defer session.Close()
+
+			var triggers []GitTrigger
+			c := session.DB(dbName).C("gitTriggers")
+			err = c.Find(bson.M{"provider": provider, "branch": event.Branch}).All(&triggers)
+			if err != nil {
+				http.Error(w, err.Error(), 500)
+				return
+			}
+			for _, t := range triggers {
+				if !tenants[tenantOfConfig(t.ConfigName)] || !event.matches(t) {
+					continue
+				}
+				logURL := "http://" + r.Host + "/v1/configuration/" + displayName(t.ConfigName) + "/plan"
+				started = append(started, planGitEvent(r.Context(), s, t, event, logURL))
+			}
+		}
+
+		output, err := json.MarshalIndent(started, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(202)
+		w.Write(output)
+	}
+}
+
+func (e *gitEvent) matches(t GitTrigger) bool {
+	if e.Number != 0 && !t.PullRequests {
+		return false
+	}
+	for _, u := range e.RepoURLs {
+		if u != "" && normalizeRepoURL(u) == t.Repository {
+			return true
+		}
+	}
+	return false
+}
+
+// planGitEvent runs a plan for the event and reports the outcome back to the
+// git hosting service as a commit status and, for pull requests, a comment.
+func planGitEvent(ctx context.Context, s *mgo.Session, t GitTrigger, event *gitEvent, logURL string) ActionResponse {
+	client := scmClients[event.Provider]
+
+	runner := actionRunners["plan"]
+	if event.Number != 0 {
+		runner = pullRequestPlanRunner(event.FetchRef)
+	}
+
+	actionResponse := startActionWith(ctx, s, t.ConfigName, "plan", logURL, t.Webhook, runner, nil, func(result ActionResponse) {
+		stdout, _, _ := readLogFile(result.ActionID)
+		summary := changeSummary(stdout)
+		if summary == "" {
+			summary = "Plan completed"
+		}
+		state := "success"
+		if result.Status != "Completed" {
+			state = "failure"
+			summary = "Plan failed"
+		}
+		targetURL := logURL + "/" + result.ActionID + ".out"
+
+		logger := Log.With("config", t.ConfigName, "action", "plan", "action_id", result.ActionID)
+		err := client.SetCommitStatus(event.RepoID, event.SHA, CommitStatus{State: state, TargetURL: targetURL, Description: summary})
+		if err != nil {
+			logger.Error("Failed to set the commit status", "error", err)
+		}
+		if event.Number != 0 {
+			body := fmt.Sprintf("**terraform plan** for `%s` at %s: %s\n\n[See Output Logs](%s)", t.ConfigName, event.SHA, summary, targetURL)
+			err = client.Comment(event.RepoID, event.Number, body)
+			if err != nil {
+				logger.Error("Failed to comment on the pull request", "pull_request", event.Number, "error", err)
+			}
+		}
+	})
+
+	err := client.SetCommitStatus(event.RepoID, event.SHA, CommitStatus{
+		State:       "pending",
+		TargetURL:   logURL + "/" + actionResponse.ActionID + ".out",
+		Description: "Plan in progress",
+	})
+	if err != nil {
+		Log.ErrorContext(ctx, "Failed to set the commit status", "action_id", actionResponse.ActionID, "error", err)
+	}
+	return actionResponse
+}
+
+// pullRequestPlanRunner plans the head of a pull request in a separate worktree
+// so the branch checked out for the configuration is left untouched. Only pull
+// requests from branches of the repository itself get here.
+func pullRequestPlanRunner(fetchRef string) actionRunner {
+	return func(ctx context.Context, confDir, repoName, randomID string) error {
+		tmpDir, err := ioutil.TempDir("", displayName(repoName)+"-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmpDir)
+
+		workDir := path.Join(tmpDir, displayName(repoName))
+		err = addWorktree(repoName, fetchRef, workDir)
+		if err != nil {
+			return err
+		}
+		defer removeWorktree(repoName, workDir)
+
+		vars, err := ioutil.ReadFile(path.Join(confDir, "terraform.tfvars"))
+		if err == nil {
+			err = ioutil.WriteFile(path.Join(workDir, "terraform.tfvars"), vars, 0644)
+		}
+		if err != nil {
+			return err
+		}
+
+		return inWorkspace(workDir, repoName, randomID, func(ws *workspace) error {
+			err := TerraformInit(ws.Dir, repoName, &planTimeOut, randomID)
+			if err != nil {
+				return err
+			}
+			return TerraformPlan(ws.Dir, repoName, &planTimeOut, randomID)
+		})
+	}
+}
+
+// gitWebhookTenants returns the tenants whose secret verifies the webhook.
+func gitWebhookTenants(verify func(secret string) bool) map[string]bool {
+	tenants := map[string]bool{}
+	for tenant, secret := range gitWebhookSecrets {
+		if verify(secret) {
+			tenants[tenant] = true
+		}
+	}
+	return tenants
+}
+
+func verifyGitHubSignature(secret, signature string, body []byte) bool {
+	if secret == "" || !strings.HasPrefix(signature, "sha256=") {
+		return false
+	}
+	got := strings.ToLower(strings.TrimPrefix(signature, "sha256="))
+	return hmac.Equal([]byte(got), []byte(signPayload(secret, body)))
+}
+
+func verifyGitLabToken(secret, token string) bool {
+	return secret != "" && subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+}
+
+// parseGitHubEvent returns nil for events that do not need a plan.
+func parseGitHubEvent(eventType string, body []byte) (*gitEvent, error) {
+	var payload struct {
+		Ref         string `json:"ref"`
+		After       string `json:"after"`
+		Deleted     bool   `json:"deleted"`
+		Action      string `json:"action"`
+		Number      int    `json:"number"`
+		PullRequest struct {
+			Head struct {
+				SHA  string `json:"sha"`
+				Repo struct {
+					FullName string `json:"full_name"`
+				} `json:"repo"`
+			} `json:"head"`
+			Base struct {
+				Ref string `json:"ref"`
+			} `json:"base"`
+		} `json:"pull_request"`
+		Repository struct {
+			FullName string `json:"full_name"`
+			CloneURL string `json:"clone_url"`
+			SSHURL   string `json:"ssh_url"`
+			HTMLURL  string `json:"html_url"`
+		} `json:"repository"`
+	}
+	err := json.Unmarshal(body, &payload)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &gitEvent{
+		Provider: "github",
+		RepoID:   payload.Repository.FullName,
+		RepoURLs: []string{payload.Repository.CloneURL, payload.Repository.SSHURL, payload.Repository.HTMLURL},
+	}
+	switch eventType {
+	case "push":
+		if payload.Deleted || !strings.HasPrefix(payload.Ref, "refs/heads/") {
+			return nil, nil
+		}
+		event.Branch = strings.TrimPrefix(payload.Ref, "refs/heads/")
+		event.SHA = payload.After
+	case "pull_request":
+		if payload.Action != "opened" && payload.Action != "synchronize" && payload.Action != "reopened" {
+			return nil, nil
+		}
+		event.Branch = payload.PullRequest.Base.Ref
+		event.SHA = payload.PullRequest.Head.SHA
+		event.Number = payload.Number
+		event.FetchRef = fmt.Sprintf("pull/%d/head", payload.Number)
+		// The head repo is null when the fork was deleted.
+		event.Fork = payload.PullRequest.Head.Repo.FullName != payload.Repository.FullName
+	default:
+		return nil, nil
+	}
+	return event, nil
+}
+
+// parseGitLabEvent returns nil for events that do not need a plan.
+func parseGitLabEvent(eventType string, body []byte) (*gitEvent, error) {
+	var payload struct {
+		Ref              string `json:"ref"`
+		CheckoutSHA      string `json:"checkout_sha"`
+		ObjectAttributes struct {
+			IID             int    `json:"iid"`
+			SourceProjectID int    `json:"source_project_id"`
+			TargetProjectID int    `json:"target_project_id"`
+			Action          string `json:"action"`
+			TargetBranch    string `json:"target_branch"`
+			LastCommit      struct {
+				ID string `json:"id"`
+			} `json:"last_commit"`
+		} `json:"object_attributes"`
+		Project struct {
+			PathWithNamespace string `json:"path_with_namespace"`
+			GitHTTPURL        string `json:"git_http_url"`
+			GitSSHURL         string `json:"git_ssh_url"`
+			WebURL            string `json:"web_url"`
+		} `json:"project"`
+	}
+	err := json.Unmarshal(body, &payload)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &gitEvent{
+		Provider: "gitlab",
+		RepoID:   payload.Project.PathWithNamespace,
+		RepoURLs: []string{payload.Project.GitHTTPURL, payload.Project.GitSSHURL, payload.Project.WebURL},
+	}
+	switch eventType {
+	case "Push Hook":
+		// checkout_sha is empty when the branch was deleted
+		if payload.CheckoutSHA == "" || !strings.HasPrefix(payload.Ref, "refs/heads/") {
+			return nil, nil
+		}
+		event.Branch = strings.TrimPrefix(payload.Ref, "refs/heads/")
+		event.SHA = payload.CheckoutSHA
+	case "Merge Request Hook":
+		a := payload.ObjectAttributes.Action
+		if a != "open" && a != "update" && a != "reopen" {
+			return nil, nil
+		}
+		event.Branch = payload.ObjectAttributes.TargetBranch
+		event.SHA = payload.ObjectAttributes.LastCommit.ID
+		event.Number = payload.ObjectAttributes.IID
+		event.FetchRef = fmt.Sprintf("merge-requests/%d/head", payload.ObjectAttributes.IID)
+		event.Fork = payload.ObjectAttributes.SourceProjectID != payload.ObjectAttributes.TargetProjectID
+	default:
+		return nil, nil
+	}
+	return event, nil
+}
+
+var scpLikeURL = regexp.MustCompile(`^[\w.-]+@([\w.-]+):(.*)$`)
+
+// normalizeRepoURL reduces the https, ssh and web urls of a repository to the
+// same host/path form so that they can be compared.
+func normalizeRepoURL(u string) string {
+	u = strings.TrimSpace(strings.ToLower(u))
+	if m := scpLikeURL.FindStringSubmatch(u); m != nil {
+		u = m[1] + "/" + m[2]
+	}
+	if i := strings.Index(u, "://"); i >= 0 {
+		u = u[i+3:]
+	}
+	if i := strings.Index(u, "@"); i >= 0 && i < strings.Index(u+"/", "/") {
+		u = u[i+1:]
+	}
+	u = strings.TrimSuffix(strings.TrimSuffix(u, "/"), ".git")
+	return u
+}
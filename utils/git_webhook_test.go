@@ -0,0 +1,310 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func hubSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyGitWebhook(t *testing.T) {
+	body := []byte(`{"ref": "refs/heads/master"}`)
+
+	tests := []struct {
+		name, secret, signature, token string
+		github, gitlab                 bool
+	}{
+		{"signed", "s3cret", hubSignature("s3cret", body), "s3cret", true, true},
+		{"other secret", "s3cret", hubSignature("other", body), "other", false, false},
+		{"prefix of the secret", "s3cret", hubSignature("s3cre", body), "s3cre", false, false},
+		{"sha1", "s3cret", "sha1=" + hubSignature("s3cret", body)[len("sha256="):], "", false, false},
+		{"not hex", "s3cret", "sha256=zz", "", false, false},
+		{"unsigned", "s3cret", "", "", false, false},
+		{"no secret configured", "", hubSignature("", body), "", false, false},
+	}
+	for _, tt := range tests {
+		if got := verifyGitHubSignature(tt.secret, tt.signature, body); got != tt.github {
+			t.Errorf("%s: github signature verified %v, want %v", tt.name, got, tt.github)
+		}
+		if got := verifyGitLabToken(tt.secret, tt.token); got != tt.gitlab {
+			t.Errorf("%s: gitlab token verified %v, want %v", tt.name, got, tt.gitlab)
+		}
+	}
+}
+
+func TestGitWebhookTenants(t *testing.T) {
+	defer func(secrets map[string]string) { gitWebhookSecrets = secrets }(gitWebhookSecrets)
+	gitWebhookSecrets = map[string]string{defaultTenant: "s3cret", "acme": "acme-s3cret", "globex": "globex-s3cret"}
+	body := []byte(`{"ref": "refs/heads/master"}`)
+
+	tests := []struct {
+		name, secret string
+		want         []string
+	}{
+		{"default", "s3cret", []string{defaultTenant}},
+		{"acme", "acme-s3cret", []string{"acme"}},
+		{"unknown", "other", nil},
+	}
+	for _, tt := range tests {
+		signature := hubSignature(tt.secret, body)
+		github := gitWebhookTenants(func(secret string) bool { return verifyGitHubSignature(secret, signature, body) })
+		gitlab := gitWebhookTenants(func(secret string) bool { return verifyGitLabToken(secret, tt.secret) })
+		for _, tenants := range []map[string]bool{github, gitlab} {
+			if len(tenants) != len(tt.want) {
+				t.Errorf("%s: verified for %v, want %v", tt.name, tenants, tt.want)
+			}
+			for _, tenant := range tt.want {
+				if !tenants[tenant] {
+					t.Errorf("%s: not verified for %s", tt.name, tenant)
+				}
+			}
+		}
+	}
+}
+
+func TestGitWebhookSignature(t *testing.T) {
+	defer func(secrets map[string]string) { gitWebhookSecrets = secrets }(gitWebhookSecrets)
+	gitWebhookSecrets = map[string]string{defaultTenant: "s3cret"}
+
+	r := mux.NewRouter()
+	r.HandleFunc("/v1/webhooks/{provider}", GitWebhookHandler(nil))
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	body := []byte(`{"zen": "Keep it logically awesome."}`)
+	tests := []struct {
+		name     string
+		provider string
+		header   http.Header
+		code     int
+	}{
+		{"github signed", "github", http.Header{"X-Github-Event": {"ping"}, "X-Hub-Signature-256": {"sha256=" + signPayload("s3cret", body)}}, 202},
+		{"github upper case", "github", http.Header{"X-Github-Event": {"ping"}, "X-Hub-Signature-256": {"sha256=" + string(bytes.ToUpper([]byte(signPayload("s3cret", body))))}}, 202},
+		{"github other secret", "github", http.Header{"X-Github-Event": {"ping"}, "X-Hub-Signature-256": {"sha256=" + signPayload("other", body)}}, 401},
+		{"github sha1", "github", http.Header{"X-Github-Event": {"ping"}, "X-Hub-Signature": {"sha1=" + signPayload("s3cret", body)}}, 401},
+		{"github unsigned", "github", http.Header{"X-Github-Event": {"ping"}}, 401},
+		{"gitlab token", "gitlab", http.Header{"X-Gitlab-Event": {"System Hook"}, "X-Gitlab-Token": {"s3cret"}}, 202},
+		{"gitlab other token", "gitlab", http.Header{"X-Gitlab-Event": {"System Hook"}, "X-Gitlab-Token": {"s3cre"}}, 401},
+		{"unknown provider", "bitbucket", http.Header{}, 404},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("POST", srv.URL+"/v1/webhooks/"+tt.provider, bytes.NewReader(body))
+		for k, v := range tt.header {
+			req.Header[k] = v
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.code {
+			t.Errorf("%s: got %d, want %d", tt.name, resp.StatusCode, tt.code)
+		}
+	}
+
+	// Nothing is accepted while no secret is configured.
+	gitWebhookSecrets = nil
+	req, _ := http.NewRequest("POST", srv.URL+"/v1/webhooks/github", bytes.NewReader(body))
+	req.Header.Set("X-GitHub-Event", "ping")
+	req.Header.Set("X-Hub-Signature-256", "sha256="+signPayload("", body))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 401 {
+		t.Errorf("without a secret: got %d, want 401", resp.StatusCode)
+	}
+}
+
+func TestParseForkPullRequests(t *testing.T) {
+	github := func(head string) []byte {
+		return []byte(`{"action": "opened", "number": 7,
+			"pull_request": {"head": {"sha": "abc", "repo": ` + head + `}, "base": {"ref": "master"}},
+			"repository": {"full_name": "acme/infra"}}`)
+	}
+	gitlab := func(source int) []byte {
+		b, _ := json.Marshal(map[string]interface{}{
+			"object_attributes": map[string]interface{}{
+				"iid": 7, "action": "open", "target_branch": "master",
+				"source_project_id": source, "target_project_id": 12,
+			},
+			"project": map[string]interface{}{"path_with_namespace": "acme/infra"},
+		})
+		return b
+	}
+
+	tests := []struct {
+		name, provider string
+		body           []byte
+		fork           bool
+	}{
+		{"github branch", "github", github(`{"full_name": "acme/infra"}`), false},
+		{"github fork", "github", github(`{"full_name": "mallory/infra"}`), true},
+		{"github deleted fork", "github", github(`null`), true},
+		{"gitlab branch", "gitlab", gitlab(12), false},
+		{"gitlab fork", "gitlab", gitlab(99), true},
+	}
+	for _, tt := range tests {
+		event, err := parseGitHubEvent("pull_request", tt.body)
+		if tt.provider == "gitlab" {
+			event, err = parseGitLabEvent("Merge Request Hook", tt.body)
+		}
+		if err != nil || event == nil {
+			t.Fatalf("%s: got %v, %v", tt.name, event, err)
+		}
+		if event.Number != 7 || event.Branch != "master" || event.Fork != tt.fork {
+			t.Errorf("%s: got %+v, want pull request 7 to master, fork %v", tt.name, event, tt.fork)
+		}
+	}
+}
+
+func TestParseGitEvents(t *testing.T) {
+	githubRepo := `"repository": {"full_name": "acme/infra", "clone_url": "https://github.com/acme/infra.git",
+		"ssh_url": "git@github.com:acme/infra.git", "html_url": "https://github.com/acme/infra"}`
+	gitlabProject := `"project": {"path_with_namespace": "acme/infra", "git_http_url": "https://gitlab.com/acme/infra.git",
+		"git_ssh_url": "git@gitlab.com:acme/infra.git", "web_url": "https://gitlab.com/acme/infra"}`
+
+	tests := []struct {
+		name, provider, eventType, body string
+		want                            *gitEvent
+	}{
+		{"github push", "github", "push",
+			`{"ref": "refs/heads/master", "after": "abc", ` + githubRepo + `}`,
+			&gitEvent{Branch: "master", SHA: "abc"}},
+		{"github branch deleted", "github", "push",
+			`{"ref": "refs/heads/master", "deleted": true, ` + githubRepo + `}`, nil},
+		{"github tag", "github", "push",
+			`{"ref": "refs/tags/v1", "after": "abc", ` + githubRepo + `}`, nil},
+		{"github pull request", "github", "pull_request",
+			`{"action": "synchronize", "number": 7, "pull_request": {"head": {"sha": "def", "repo": {"full_name": "acme/infra"}},
+				"base": {"ref": "master"}}, ` + githubRepo + `}`,
+			&gitEvent{Branch: "master", SHA: "def", Number: 7, FetchRef: "pull/7/head"}},
+		{"github pull request closed", "github", "pull_request",
+			`{"action": "closed", "number": 7, ` + githubRepo + `}`, nil},
+		{"github ping", "github", "ping", `{"zen": "Design for failure."}`, nil},
+		{"gitlab push", "gitlab", "Push Hook",
+			`{"ref": "refs/heads/master", "checkout_sha": "abc", ` + gitlabProject + `}`,
+			&gitEvent{Branch: "master", SHA: "abc"}},
+		{"gitlab branch deleted", "gitlab", "Push Hook",
+			`{"ref": "refs/heads/master", "checkout_sha": null, ` + gitlabProject + `}`, nil},
+		{"gitlab merge request", "gitlab", "Merge Request Hook",
+			`{"object_attributes": {"iid": 3, "action": "update", "target_branch": "master", "last_commit": {"id": "def"},
+				"source_project_id": 12, "target_project_id": 12}, ` + gitlabProject + `}`,
+			&gitEvent{Branch: "master", SHA: "def", Number: 3, FetchRef: "merge-requests/3/head"}},
+		{"gitlab merge request merged", "gitlab", "Merge Request Hook",
+			`{"object_attributes": {"iid": 3, "action": "merge", "target_branch": "master"}, ` + gitlabProject + `}`, nil},
+	}
+	for _, tt := range tests {
+		event, err := parseGitHubEvent(tt.eventType, []byte(tt.body))
+		if tt.provider == "gitlab" {
+			event, err = parseGitLabEvent(tt.eventType, []byte(tt.body))
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if tt.want == nil {
+			if event != nil {
+				t.Errorf("%s: got %+v, want no event", tt.name, event)
+			}
+			continue
+		}
+		if event == nil || event.Branch != tt.want.Branch || event.SHA != tt.want.SHA || event.Number != tt.want.Number ||
+			event.FetchRef != tt.want.FetchRef || event.RepoID != "acme/infra" {
+			t.Errorf("%s: got %+v, want %+v", tt.name, event, tt.want)
+			continue
+		}
+
+		// Every url of the repository matches the trigger, whatever form it was given in.
+		for _, repo := range []string{"https://" + tt.provider + ".com/acme/infra", "git@" + tt.provider + ".com:acme/infra.git", "HTTPS://" + tt.provider + ".com/Acme/Infra.git/"} {
+			trigger := GitTrigger{Provider: tt.provider, Repository: normalizeRepoURL(repo), Branch: "master", PullRequests: true}
+			if !event.matches(trigger) {
+				t.Errorf("%s: does not match %s", tt.name, repo)
+			}
+			trigger.PullRequests = false
+			if event.matches(trigger) != (event.Number == 0) {
+				t.Errorf("%s: matches a trigger without pull requests", tt.name)
+			}
+		}
+		other := GitTrigger{Provider: tt.provider, Repository: normalizeRepoURL("https://" + tt.provider + ".com/acme/other"), PullRequests: true}
+		if event.matches(other) {
+			t.Errorf("%s: matches another repository", tt.name)
+		}
+	}
+}
+
+func TestSetCommitStatus(t *testing.T) {
+	var got struct {
+		Method, Path, Auth, Token string
+		Body                      map[string]string
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.Method, got.Path = r.Method, r.URL.EscapedPath()
+		got.Auth, got.Token = r.Header.Get("Authorization"), r.Header.Get("PRIVATE-TOKEN")
+		b, _ := ioutil.ReadAll(r.Body)
+		got.Body = nil
+		json.Unmarshal(b, &got.Body)
+		w.WriteHeader(201)
+	}))
+	defer srv.Close()
+
+	long := string(bytes.Repeat([]byte("x"), 300))
+	status := CommitStatus{State: "failure", TargetURL: "http://tf/v1/configuration/infra/plan/a1.out", Description: long}
+
+	gh := &githubClient{baseURL: srv.URL, token: "ghtoken"}
+	if err := gh.SetCommitStatus("acme/infra", "abc123", status); err != nil {
+		t.Fatal(err)
+	}
+	if got.Method != "POST" || got.Path != "/repos/acme/infra/statuses/abc123" || got.Auth != "token ghtoken" {
+		t.Errorf("github: got %s %s with %q", got.Method, got.Path, got.Auth)
+	}
+	if got.Body["state"] != "failure" || got.Body["context"] != scmContext || got.Body["target_url"] != status.TargetURL || len(got.Body["description"]) != 140 {
+		t.Errorf("github: got body %v", got.Body)
+	}
+	if err := gh.Comment("acme/infra", 7, "plan output"); err != nil {
+		t.Fatal(err)
+	}
+	if got.Path != "/repos/acme/infra/issues/7/comments" || got.Body["body"] != "plan output" {
+		t.Errorf("github: got comment %s %v", got.Path, got.Body)
+	}
+
+	gl := &gitlabClient{baseURL: srv.URL, token: "gltoken"}
+	if err := gl.SetCommitStatus("acme/infra", "abc123", status); err != nil {
+		t.Fatal(err)
+	}
+	if got.Path != "/projects/acme%2Finfra/statuses/abc123" || got.Token != "gltoken" {
+		t.Errorf("gitlab: got %s %s with %q", got.Method, got.Path, got.Token)
+	}
+	if got.Body["state"] != "failed" || got.Body["name"] != scmContext || len(got.Body["description"]) != 255 {
+		t.Errorf("gitlab: got body %v", got.Body)
+	}
+	if err := gl.Comment("acme/infra", 3, "plan output"); err != nil {
+		t.Fatal(err)
+	}
+	if got.Path != "/projects/acme%2Finfra/merge_requests/3/notes" || got.Body["body"] != "plan output" {
+		t.Errorf("gitlab: got comment %s %v", got.Path, got.Body)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(422)
+	}))
+	defer failing.Close()
+	gh.baseURL = failing.URL
+	if err := gh.SetCommitStatus("acme/infra", "abc123", status); err == nil {
+		t.Errorf("a refused status is not reported")
+	}
+}
@@ -1,17 +1,14 @@
-// @SubApi ibmcloud-terraform-provider [/v1]
-// @SubApi Allows you access ibm cloud terraform provider api [/v1]
-
 package utils
 
 import (
-	"crypto/rand"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"os"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -28,14 +25,19 @@ var currentOps = make(map[string]chan StatusResponse)
 
 // ConfigRequest -
 type ConfigRequest struct {
-	GitURL        string            `json:"git_url,required" description:"The git url of your configuraltion"`
-	VariableStore *VariablesRequest `json:"variablestore,omitempty" description:"The environments' variable store"`
-	LOGLEVEL      string            `json:"log_level,omitempty" description:"The log level defing by user."`
+	GitURL         string            `json:"git_url,required" description:"The git url of your configuraltion"`
+	VariableStore  *VariablesRequest `json:"variablestore,omitempty" description:"The environments' variable store"`
+	LOGLEVEL       string            `json:"log_level,omitempty" description:"The log level defing by user."`
+	PreventDestroy bool              `json:"prevent_destroy,omitempty" description:"Refuse to destroy or delete the configuration"`
+	TTLRequest
 }
 
 // ConfigResponse -
 type ConfigResponse struct {
 	ConfigName string `json:"config_name,required" description:"configuration name"`
+	Action     string `json:"action" description:"init, the action cloning and initializing the configuration"`
+	ActionID   string `json:"action_id" description:"Follow it at /v1/configuration/{repo_name}/init/{action_id}/status"`
+	Status     string `json:"status"`
 }
 
 // StatusResponse -
@@ -71,37 +73,11 @@ type EnvironmentVariableRequest struct {
 	Value string `json:"value,required" binding:"required" description:"The variable's value"`
 }
 
-var currentDir = os.Getenv("MOUNT_DIR")
-
-var logDir = currentDir + "/log/"
-
-var stateDir = currentDir + "/state"
-
-func init() {
-
-	if currentDir == "" {
-		panic("MOUNT_DIR is not set. Please set MOUNT_DIR to continue")
-	}
-
-	if _, err := os.Stat(logDir); os.IsNotExist(err) {
-		os.MkdirAll(logDir, os.ModePerm)
-	}
-	if _, err := os.Stat(stateDir); os.IsNotExist(err) {
-		os.MkdirAll(stateDir, os.ModePerm)
-	}
-
-}
+// currentDir holds the configurations, logDir and stateDir their logs and
+// state. They are set by Configure.
+var currentDir, logDir, stateDir string
 
-//ConfHandler handles request to kickoff git clone of the repo.
-// @Title ConfHandler
-// @Description clone the configuration repo
-// @Accept  json
-// @Produce  json
-// @Param   body     body     ConfigRequest   true "request body"
-// @Success 200 {object} ConfigResponse
-// @Failure 500 {object} string
-// @Failure 400 {object} string
-// @Router /v1/configuration [post]
+// ConfHandler handles request to kickoff git clone of the repo.
 func ConfHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 
@@ -121,368 +97,329 @@ func ConfHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, err.Error(), 500)
 			return
 		}
-		log.Println(msg.GitURL)
+		Log.DebugContext(r.Context(), "Creating a configuration", "git_url", msg.GitURL)
 		if msg.GitURL == "" {
 			w.WriteHeader(400)
 			w.Write([]byte("EMPTY GIT URL"))
 			return
 		}
 
-		if msg.LOGLEVEL != "" {
-			os.Setenv("TF_LOG", msg.LOGLEVEL)
+		var ttl time.Duration
+		if msg.TTL != "" {
+			ttl, err = parseTTL(msg.TTL)
+			if err == nil && s == nil {
+				err = errMongoOnly("ttl")
+			}
+			if err != nil {
+				http.Error(w, err.Error(), 400)
+				return
+			}
 		}
 
-		log.Println("Will clone git repo")
-
-		_, configName, err := cloneRepo(msg)
+		// Updating an existing configuration needs the operator role on it,
+		// creating one makes the caller its admin. The name is reserved with
+		// the store record, so that of two concurrent requests only one
+		// creates the configuration.
+		configName, err := configNameFromURL(msg.GitURL)
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		configName = qualifiedName(tenantOf(r), configName)
+		caller, _ := IdentityFrom(r)
+		created, err := reserveConfiguration(configName, caller.Subject, msg)
 		if err != nil {
 			http.Error(w, err.Error(), 500)
 			return
 		}
-		log.Println("\n", configName)
+		if !created && !authorize(w, r, configName, RoleOperator) {
+			return
+		}
 
-		response.ConfigName = configName
-		log.Println(response)
+		if msg.LOGLEVEL != "" {
+			os.Setenv("TF_LOG", msg.LOGLEVEL)
+		}
 
-		output, err := json.MarshalIndent(response, "", "  ")
-		if err != nil {
-			return
+		// A configuration the caller creates is its own right away, so that
+		// it can follow the init action.
+		if created && caller.Subject != "" {
+			err = grantRole(configName, caller.Subject, RoleAdmin, caller.Subject)
+			if err != nil {
+				deleteConfiguration(s, configName)
+				http.Error(w, err.Error(), 500)
+				return
+			}
 		}
 
-		confDir := path.Join(currentDir, configName)
+		// Clone and init run as the init action of the configuration, they
+		// can take longer than a request when providers are downloaded.
+		tenant := tenantOf(r)
+		logURL := "http://" + r.Host + "/v1/configuration/" + displayName(configName) + "/init"
+		destroyURL := "http://" + r.Host + "/v1/configuration/" + displayName(configName) + "/destroy"
+		webhook := r.Header.Get("SLACK_WEBHOOK_URL")
+		actionResponse := startActionWith(r.Context(), s, configName, "init", logURL, webhook, func(ctx context.Context, confDir, repoName, randomID string) error {
+			Log.InfoContext(ctx, "Cloning the git repo", "git_url", msg.GitURL)
+			out, _, err := cloneRepo(ctx, msg, tenant)
+			appendActionLog(randomID, out, err)
+			if err != nil {
+				// A name reserved by this request is free again.
+				if created {
+					deleteConfiguration(s, configName)
+				}
+				return err
+			}
+			err = saveConfiguration(configName, caller.Subject, msg)
+			if err != nil {
+				return err
+			}
 
-		b = make([]byte, 10)
-		rand.Read(b)
-		randomID := fmt.Sprintf("%x", b)
+			// The providers and modules installed by init are kept for the actions.
+			err = inWorkspace(confDir, configName, randomID, func(ws *workspace) error {
+				return TerraformInit(ws.Dir, configName, &planTimeOut, randomID)
+			}, ".terraform", ".terraform.lock.hcl")
+			if err == nil && ttl > 0 {
+				err = setTTL(s, configName, ttl, msg.DeleteOnExpiry, destroyURL, webhook)
+			}
+			return err
+		}, nil, nil)
+		noteAudit(r, configName, actionResponse.ActionID)
+
+		response.ConfigName = displayName(configName)
+		response.Action = actionResponse.Action
+		response.ActionID = actionResponse.ActionID
+		response.Status = actionResponse.Status
 
-		err = TerraformInit(confDir, configName, &planTimeOut, randomID)
+		output, err := json.MarshalIndent(response, "", "  ")
 		if err != nil {
 			http.Error(w, err.Error(), 500)
 			return
 		}
 		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(202)
 		w.Write(output)
 	}
 }
 
-//ConfDeleteHandler handles request to kickoff delete for the configuration repo.
-// @Title ConfDeleteHandler
-// @Description delete the configuration repo
-// @Param   repo_name     path    string     true "Some ID"
-// @Accept  json
-// @Produce  json
-// @Success 200 {object} string
-// @Failure 404 {object} string
-// @Router /v1/configuration/{repo_name} [delete]
-func ConfDeleteHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "DELETE" {
-		http.Error(w, "Invalid request method.", 405)
+// reserveConfiguration creates the store record of the configuration and
+// tells whether this request created it. A configuration cloned before its
+// record was kept, e.g. by the memory store, is not created again: its
+// record is saved once the caller is authorized, so that a caller without a
+// role on it can not set its creator or prevent_destroy.
+func reserveConfiguration(configName, createdBy string, msg ConfigRequest) (bool, error) {
+	if _, err := os.Stat(configDir(configName)); err == nil {
+		return false, nil
+	}
+	err := store.CreateConfiguration(Configuration{
+		ConfigName:     configName,
+		GitURL:         msg.GitURL,
+		CreatedBy:      createdBy,
+		Created:        time.Now(),
+		PreventDestroy: msg.PreventDestroy,
+	})
+	if err == ErrExists {
+		return false, nil
 	}
-
-	vars := mux.Vars(r)
-	repoName := vars["repo_name"]
-
-	err := removeRepo(currentDir, repoName)
 	if err != nil {
-		w.WriteHeader(404)
-		log.Println(err)
-		w.Write([]byte(fmt.Sprintf("There is no config repo file for this request.")))
-		return
+		return false, err
 	}
+	return true, nil
 }
 
-//PlanHandler handles request to run terraform plan.
-// @Title PlanHandler
-// @Description Execute plan for the configuration.
-// @Param   SLACK_WEBHOOK_URL     header    string     false "provide slack webhook url"
-// @Param   repo_name     path    string     true "Repo Name"
-// @Accept  json
-// @Produce  json
-// @Success 202 {object} ActionResponse
-// @Failure 404 {object} string
-// @Failure 500 {object} string
-// @Router /v1/configuration/{repo_name}/plan [post]
-func PlanHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
-	return func(w http.ResponseWriter, r *http.Request) {
-		session := s.Copy()
-		defer session.Close()
-
-		webhook := r.Header.Get("SLACK_WEBHOOK_URL")
-		vars := mux.Vars(r)
-		repoName := vars["repo_name"]
+// configCollections hold the schedules, subscriptions and status records
+// of a configuration, they go away with it.
+var configCollections = []string{
+	"schedules",
+	"notificationChannels",
+	"webhookTargets",
+	"driftStatus",
+	"environmentTTL",
+	"gitTriggers",
+	"destroyConfirmations",
+}
 
-		var actionResponse ActionResponse
-		var statusResponse StatusResponse
+// deleteConfiguration removes the cloned repo of the configuration together
+// with its store record, the roles granted on it and, with mongo, everything
+// the configuration is subscribed to.
+func deleteConfiguration(s *mgo.Session, repoName string) error {
+	err := removeRepo(repoName)
+	if err != nil {
+		return err
+	}
+	err = store.DeleteConfiguration(repoName)
+	if err != nil {
+		return err
+	}
+	err = store.DeleteGrants(repoName)
+	if err != nil || s == nil {
+		return err
+	}
 
-		log.Println("Url Param 'repo name' is: " + repoName)
-		confDir := path.Join(currentDir, repoName)
+	session := s.Copy()
+	defer session.Close()
+	for _, name := range configCollections {
+		_, err = session.DB(dbName).C(name).RemoveAll(bson.M{"configname": repoName})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-		b := make([]byte, 10)
-		rand.Read(b)
-		randomID := fmt.Sprintf("%x", b)
+// saveConfiguration records the configuration cloned for the request. A
+// configuration cloned again keeps its creator and settings.
+func saveConfiguration(configName, createdBy string, msg ConfigRequest) error {
+	conf, err := store.FindConfiguration(configName)
+	if err == ErrNotFound {
+		conf, err = Configuration{ConfigName: configName, CreatedBy: createdBy, Created: time.Now()}, nil
+	}
+	if err != nil {
+		return err
+	}
+	conf.GitURL = msg.GitURL
+	if msg.PreventDestroy {
+		conf.PreventDestroy = true
+	}
+	return store.SaveConfiguration(conf)
+}
 
-		outURL := "http://" + r.Host + "/" + r.URL.Path + "/" + randomID + ".out"
-		errURL := "http://" + r.Host + "/" + r.URL.Path + "/" + randomID + ".err"
+// ConfDeleteHandler handles request to kickoff delete for the configuration repo.
+func ConfDeleteHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" {
+			http.Error(w, "Invalid request method.", 405)
+			return
+		}
 
-		// Post to slack that the action has started and the link logs
-		ResultToSlack(outURL, errURL, "plan", randomID, "In-Progress", webhook)
+		repoName := configOf(r)
 
-		go func() {
-			pullRepo(repoName)
-			err := TerraformPlan(confDir, repoName, &planTimeOut, randomID)
+		if _, err := os.Stat(configDir(repoName)); err != nil {
+			http.Error(w, "There is no config repo file for this request.", 404)
+			return
+		}
+		// The action would lose its repo and state under it.
+		if actionInProgress(repoName) {
+			http.Error(w, fmt.Sprintf("An action of %s is in progress, delete it once the action is done", displayName(repoName)), 409)
+			return
+		}
+		err := destroyPrevented(repoName)
+		if err != nil {
+			http.Error(w, err.Error(), 409)
+			return
+		}
+		if r.URL.Query().Get("force") != "true" {
+			n, err := stateResources(repoName)
 			if err != nil {
-				statusResponse.Error = err.Error()
-				statusResponse.Status = "Failed"
-
-				// Update the status in the db in case it is failed
-				err = UpdateMongodb(s, randomID, statusResponse.Status)
-				if err != nil {
-					http.Error(w, err.Error(), 500)
-					return
-				}
+				http.Error(w, err.Error(), 500)
 				return
 			}
-			statusResponse.Status = "Completed"
-
-			// Update the status in the db in case it is completed
-			err = UpdateMongodb(s, randomID, statusResponse.Status)
-			ResultToSlack(outURL, errURL, "plan", randomID, "Completed", webhook)
-		}()
-
-		w.WriteHeader(202)
-
-		actionResponse.Action = "plan"
-		actionResponse.ConfigName = repoName
-		actionResponse.ActionID = randomID
-		actionResponse.Timestamp = time.Now().Format("20060102150405")
-		actionResponse.Status = "In-Progress"
-
-		// Make an entry in the db
-		InsertMongodb(s, actionResponse)
+			if n > 0 {
+				http.Error(w, fmt.Sprintf("The state of %s still tracks %d resources, destroy them first or delete with force=true", displayName(repoName), n), 409)
+				return
+			}
+		}
 
-		output, err := json.MarshalIndent(actionResponse, "", "  ")
+		err = deleteConfiguration(s, repoName)
 		if err != nil {
+			Log.ErrorContext(r.Context(), "Failed to delete the configuration", "error", err)
 			http.Error(w, err.Error(), 500)
-			return
 		}
-		w.Header().Set("content-type", "application/json")
-		w.Write(output)
-
 	}
 }
 
-//ApplyHandler handles request to run terraform apply.
-// @Title ApplyHandler
-// @Description Execute apply for the configuration.
-// @Param   SLACK_WEBHOOK_URL     header    string     false "provide slack webhook url"
-// @Param   repo_name     path    string     true "Repo Name"
-// @Accept  json
-// @Produce  json
-// @Success 202 {object} ActionResponse
-// @Failure 404 {object} string
-// @Failure 500 {object} string
-// @Router /v1/configuration/{repo_name}/apply [post]
+// PlanHandler handles request to run terraform plan.
+func PlanHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
+	return actionHandler(s, "plan")
+}
+
+// ApplyHandler handles request to run terraform apply.
 func ApplyHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
+	apply := actionHandler(s, "apply")
 	return func(w http.ResponseWriter, r *http.Request) {
-		session := s.Copy()
-		defer session.Close()
-
-		webhook := r.Header.Get("SLACK_WEBHOOK_URL")
-
-		var actionResponse ActionResponse
-		var statusResponse StatusResponse
-
-		vars := mux.Vars(r)
-		repoName := vars["repo_name"]
-
-		log.Println("Url Param 'repo name' is: " + repoName)
-		confDir := path.Join(currentDir, repoName)
-
-		b := make([]byte, 10)
-		rand.Read(b)
-		randomID := fmt.Sprintf("%x", b)
-
-		outURL := "http://" + r.Host + "/" + r.URL.Path + "/" + randomID + ".out"
-		errURL := "http://" + r.Host + "/" + r.URL.Path + "/" + randomID + ".err"
-		ResultToSlack(outURL, errURL, "apply", randomID, "In-Progress", webhook)
-		go func() {
+		b, err := ioutil.ReadAll(r.Body)
+		defer r.Body.Close()
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
 
-			pullRepo(repoName)
-			err := TerraformApply(confDir, stateDir, repoName, &planTimeOut, randomID)
+		// The body is optional, it carries the time to live of the environment.
+		var msg TTLRequest
+		if len(b) > 0 {
+			err = json.Unmarshal(b, &msg)
 			if err != nil {
-				statusResponse.Error = err.Error()
-				statusResponse.Status = "Failed"
-
-				err = UpdateMongodb(s, randomID, statusResponse.Status)
-				if err != nil {
-					http.Error(w, err.Error(), 500)
-					return
-				}
+				http.Error(w, err.Error(), 400)
+				return
+			}
+		}
+		if msg.TTL != "" {
+			ttl, err := parseTTL(msg.TTL)
+			if err == nil && s == nil {
+				err = errMongoOnly("ttl")
+			}
+			if err != nil {
+				http.Error(w, err.Error(), 400)
+				return
+			}
+			repoName := configOf(r)
+			err = setTTL(s, repoName, ttl, msg.DeleteOnExpiry, "http://"+r.Host+"/v1/configuration/"+displayName(repoName)+"/destroy", r.Header.Get("SLACK_WEBHOOK_URL"))
+			if err != nil {
+				http.Error(w, err.Error(), 500)
 				return
 			}
-			statusResponse.Status = "Completed"
-			ResultToSlack(outURL, errURL, "apply", randomID, statusResponse.Status, webhook)
-			err = UpdateMongodb(s, randomID, statusResponse.Status)
-
-		}()
-		w.WriteHeader(202)
-		actionResponse.Action = "apply"
-		actionResponse.ConfigName = repoName
-		actionResponse.ActionID = randomID
-		actionResponse.Timestamp = time.Now().Format("20060102150405")
-		actionResponse.Status = "In-Progress"
-
-		InsertMongodb(s, actionResponse)
-		output, err := json.MarshalIndent(actionResponse, "", "  ")
-		if err != nil {
-			http.Error(w, err.Error(), 500)
-			return
 		}
-		w.Header().Set("content-type", "application/json")
-		w.Write(output)
 
+		apply(w, r)
 	}
 }
 
-//DestroyHandler handles request to run terraform delete.
-// @Title DestroyHandler
-// @Description Execute destroy for the configuration.
-// @Param   SLACK_WEBHOOK_URL     header    string     false "provide slack webhook url"
-// @Param   repo_name     path    string     true "Repo Name"
-// @Accept  json
-// @Produce  json
-// @Success 202 {object} ActionResponse
-// @Failure 404 {object} string
-// @Failure 500 {object} string
-// @Router /v1/configuration/{repo_name}/destroy [post]
+// DestroyHandler handles request to run terraform delete.
 func DestroyHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
-
+	destroy := actionHandler(s, "destroy")
 	return func(w http.ResponseWriter, r *http.Request) {
-		session := s.Copy()
-		defer session.Close()
-
-		var actionResponse ActionResponse
-		var statusResponse StatusResponse
-
-		webhook := r.Header.Get("SLACK_WEBHOOK_URL")
-		vars := mux.Vars(r)
-		repoName := vars["repo_name"]
-
-		log.Println("Url Param 'repo name' is: " + repoName)
-		confDir := path.Join(currentDir, repoName)
-
-		b := make([]byte, 10)
-		rand.Read(b)
-		randomID := fmt.Sprintf("%x", b)
+		b, err := ioutil.ReadAll(r.Body)
+		defer r.Body.Close()
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
 
-		outURL := "http://" + r.Host + "/" + r.URL.Path + "/" + randomID + ".out"
-		errURL := "http://" + r.Host + "/" + r.URL.Path + "/" + randomID + ".err"
-		ResultToSlack(outURL, errURL, "destroy", randomID, "In-Progress", webhook)
-		go func() {
-			err := TerraformDestroy(confDir, stateDir, repoName, &planTimeOut, randomID)
+		var msg DestroyRequest
+		if len(b) > 0 {
+			err = json.Unmarshal(b, &msg)
 			if err != nil {
-				statusResponse.Error = err.Error()
-				statusResponse.Status = "Failed"
-
-				err = UpdateMongodb(s, randomID, statusResponse.Status)
-				if err != nil {
-					http.Error(w, err.Error(), 500)
-					return
-				}
+				http.Error(w, err.Error(), 400)
 				return
 			}
-			statusResponse.Status = "Completed"
-			ResultToSlack(outURL, errURL, "destroy", randomID, "Completed", webhook)
-			err = UpdateMongodb(s, randomID, statusResponse.Status)
-
-		}()
-
-		w.WriteHeader(202)
-		actionResponse.Action = "destroy"
-		actionResponse.ConfigName = repoName
-		actionResponse.ActionID = randomID
-		actionResponse.Timestamp = time.Now().Format("20060102150405")
-		actionResponse.Status = "In-Progress"
-
-		InsertMongodb(s, actionResponse)
+		}
 
-		output, err := json.MarshalIndent(actionResponse, "", "  ")
+		repoName := configOf(r)
+		err = destroyPrevented(repoName)
 		if err != nil {
-			http.Error(w, err.Error(), 500)
+			http.Error(w, err.Error(), 409)
+			return
+		}
+		if !confirmDestroy(s, w, r, repoName, msg) {
 			return
 		}
-		w.Header().Set("content-type", "application/json")
-		w.Write(output)
 
+		destroy(w, r)
 	}
 }
 
-//ShowHandler handles request to run terraform show.
-// @Title ShowHandler
-// @Description Execute show for the configuration.
-// @Param   SLACK_WEBHOOK_URL     header    string     false "provide slack webhook url"
-// @Param   repo_name     path    string     true "Repo Name"
-// @Accept  json
-// @Produce  json
-// @Success 202 {object} ActionResponse
-// @Failure 404 {object} string
-// @Failure 500 {object} string
-// @Router /v1/configuration/{repo_name}/show [post]
+// ShowHandler handles request to run terraform show.
 func ShowHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
+	return actionHandler(s, "show")
+}
 
+// actionHandler starts the action for the configuration named in the path and
+// responds with the new action's details.
+func actionHandler(s *mgo.Session, action string) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-
-		session := s.Copy()
-		defer session.Close()
-
-		var actionResponse ActionResponse
-		var statusResponse StatusResponse
-
 		webhook := r.Header.Get("SLACK_WEBHOOK_URL")
-		vars := mux.Vars(r)
+		repoName := configOf(r)
 
-		repoName := vars["repo_name"]
-
-		log.Println("Url Param 'repo name' is: " + repoName)
-		confDir := path.Join(currentDir, repoName)
-
-		b := make([]byte, 10)
-		rand.Read(b)
-		randomID := fmt.Sprintf("%x", b)
-
-		outURL := "http://" + r.Host + "/" + r.URL.Path + "/" + randomID + ".out"
-		errURL := "http://" + r.Host + "/" + r.URL.Path + "/" + randomID + ".err"
-		ResultToSlack(outURL, errURL, "show", randomID, "In-Progress", webhook)
-		go func() {
-			err := TerraformShow(confDir, stateDir, repoName, &planTimeOut, randomID)
-			if err != nil {
-				statusResponse.Error = err.Error()
-				statusResponse.Status = "Failed"
-
-				err = UpdateMongodb(s, randomID, statusResponse.Status)
-				if err != nil {
-					http.Error(w, err.Error(), 500)
-					return
-				}
-				return
-			}
-			outURL := "http://" + r.Host + "/" + r.URL.Path + "/" + randomID + ".out"
-			errURL := "http://" + r.Host + "/" + r.URL.Path + "/" + randomID + ".err"
-
-			statusResponse.Status = "Completed"
-			ResultToSlack(outURL, errURL, "show", randomID, statusResponse.Status, webhook)
-			err = UpdateMongodb(s, randomID, statusResponse.Status)
-
-		}()
-		w.WriteHeader(202)
-
-		actionResponse.Action = "show"
-		actionResponse.ConfigName = repoName
-		actionResponse.ActionID = randomID
-		actionResponse.Timestamp = time.Now().Format("20060102150405")
-		actionResponse.Status = "In-Progress"
-
-		InsertMongodb(s, actionResponse)
+		actionResponse := startAction(r.Context(), s, repoName, action, "http://"+r.Host+"/"+r.URL.Path, webhook, nil)
+		noteAudit(r, repoName, actionResponse.ActionID)
 
 		output, err := json.MarshalIndent(actionResponse, "", "  ")
 		if err != nil {
@@ -490,35 +427,27 @@ func ShowHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(202)
 		w.Write(output)
-
 	}
 }
 
-//LogHandler handles request to get the log.
-// @Title LogHandler
-// @Description Get logs for the configuration.
-// @Param   repo_name     path    string     true "repo name"
-// @Param   action_name     path    string     true "action name"
-// @Param   action_id     path    string     true "action id"
-// @Accept  json
-// @Produce  json
-// @Success 200 {object} ActionDetails
-// @Failure 404 {object} string
-// @Failure 500 {object} string
-// @Router /v1/configuration/{repo_name}/{action_name}/{action_id}/log [get]
+// LogHandler handles request to get the log.
 func LogHandler(w http.ResponseWriter, r *http.Request) {
 
 	var response ActionDetails
 
 	vars := mux.Vars(r)
-	repoName := vars["repo_name"]
+	repoName := configOf(r)
 	action := vars["action"]
 	actionID := vars["actionID"]
 
-	log.Println("Url Param 'repo name' is: " + repoName)
-	log.Println("Url Param 'action' is: " + action)
-	log.Println("Url Param 'actionID' is: " + actionID)
+	Log.DebugContext(r.Context(), "Reading the action logs", "action", action, "action_id", actionID)
+
+	if !actionOfConfig(repoName, actionID) {
+		http.Error(w, "There is no action for this request.", 404)
+		return
+	}
 
 	outFile, errFile, err := readLogFile(actionID)
 	if err != nil {
@@ -539,40 +468,32 @@ func LogHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	w.Header().Set("content-type", "application/json")
 	w.Write(output)
+}
 
+// actionOfConfig tells whether the action was run for the configuration, so
+// that logs are only served through the configuration they belong to.
+func actionOfConfig(repoName, actionID string) bool {
+	_, err := store.FindAction(repoName, actionID)
+	return err == nil
 }
 
-//StatusHandler handles request to get the action status.
-// @Title StatusHandler
-// @Description Get status of the action.
-// @Param   repo_name     path    string     true "repo name"
-// @Param   action_name     path    string     true "action name"
-// @Param   action_id     path    string     true "action id"
-// @Accept  json
-// @Produce  json
-// @Success 200 {object} StatusResponse
-// @Failure 404 {object} string
-// @Failure 500 {object} string
-// @Router /v1/configuration/{repo_name}/{action_name}/{action_id}/status [get]
+// StatusHandler handles request to get the action status.
 func StatusHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		session := s.Copy()
-		defer session.Close()
-
 		var response StatusResponse
-		var actionResponse ActionResponse
 
 		vars := mux.Vars(r)
-		repoName := vars["repo_name"]
+		repoName := configOf(r)
 		action := vars["action"]
 		actionID := vars["actionID"]
 
-		log.Println("Url Param 'repo name' is: " + repoName)
-		log.Println("Url Param 'action' is: " + action)
-		log.Println("Url Param 'actionID' is: " + actionID)
+		Log.DebugContext(r.Context(), "Reading the action status", "action", action, "action_id", actionID)
 
-		c := session.DB("action").C("actionDetails")
-		err := c.Find(bson.M{"actionid": actionID}).One(&actionResponse)
+		actionResponse, err := store.FindAction(repoName, actionID)
+		if err == ErrNotFound {
+			http.Error(w, "There is no action for this request.", 404)
+			return
+		}
 		if err != nil {
 			http.Error(w, err.Error(), 500)
 			return
@@ -588,7 +509,7 @@ func StatusHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-//ViewLogHandler handles request to retrieve the log file
+// ViewLogHandler handles request to retrieve the log file
 func ViewLogHandler(w http.ResponseWriter, r *http.Request) {
 
 	if r.Method != "GET" {
@@ -598,10 +519,17 @@ func ViewLogHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	logFile := vars["log_file"]
 
-	body, err := ioutil.ReadFile(path.Join(logDir, logFile))
+	logPath, err := withinDir(logDir, logFile)
+	var body []byte
+	if err == nil {
+		body, err = ioutil.ReadFile(logPath)
+	}
+	if err == nil && !actionOfConfig(configOf(r), strings.TrimSuffix(logFile, path.Ext(logFile))) {
+		err = fmt.Errorf("%s is not a log of %s", logFile, configOf(r))
+	}
 	if err != nil {
 		w.WriteHeader(404)
-		log.Println(err)
+		Log.WarnContext(r.Context(), "There is no such log file", "error", err)
 		w.Write([]byte(fmt.Sprintf("There is no log file for this request")))
 		return
 	}
@@ -609,29 +537,14 @@ func ViewLogHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(body)
 }
 
-//GetActionDetailsHandler handles request to get all the information for a particular action.
-// @Title GetActionDetailsHandler
-// @Description Get all the information for a particular action
-// @Param   repo_name     path    string     true "repo name"
-// @Param   action_name     path    string     true "action name"
-// @Accept  json
-// @Produce  json
-// @Success 200 {object} ActionResponse
-// @Failure 404 {object} string
-// @Failure 500 {object} string
-// @Router /v1/configuration/{repo_name}/{action_name} [get]
+// GetActionDetailsHandler handles request to get all the information for a particular action.
 func GetActionDetailsHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		session := s.Copy()
-		defer session.Close()
-
 		vars := mux.Vars(r)
+		repoName := configOf(r)
 		action := vars["action"]
 
-		var actionResponse []ActionResponse
-		c := session.DB("action").C("actionDetails")
-
-		err := c.Find(bson.M{"action": action}).All(&actionResponse)
+		actionResponse, err := store.FindActions(repoName, action)
 		if err != nil {
 			http.Error(w, err.Error(), 500)
 			return
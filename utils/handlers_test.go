@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestConfHandlerExistingRepo(t *testing.T) {
+	saved := store
+	defer func() { store = saved }()
+	SetStore(NewMemoryStore())
+	defer func(dir string) { currentDir = dir }(currentDir)
+	dir, err := ioutil.TempDir("", "conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	currentDir = dir
+
+	// Cloned before the store kept its record.
+	if err := os.MkdirAll(configDir("acme/web"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest("POST", "/v1/configuration", strings.NewReader(`{"git_url": "https://github.com/acme/web.git", "prevent_destroy": true}`))
+	r = withIdentity(r, Identity{Subject: "mallory", Method: "api_key", Tenant: "acme"})
+	w := httptest.NewRecorder()
+	ConfHandler(nil)(w, r)
+	if w.Code != 403 {
+		t.Fatalf("got %d %s, want 403", w.Code, w.Body)
+	}
+	if conf, err := store.FindConfiguration("acme/web"); err != ErrNotFound {
+		t.Errorf("the refused request recorded %+v, %v", conf, err)
+	}
+
+	// A new configuration is reserved for the caller.
+	created, err := reserveConfiguration("acme/db", "alice", ConfigRequest{GitURL: "https://github.com/acme/db.git"})
+	if err != nil || !created {
+		t.Fatalf("reserving acme/db: %v, %v", created, err)
+	}
+	if conf, err := store.FindConfiguration("acme/db"); err != nil || conf.CreatedBy != "alice" {
+		t.Errorf("acme/db is recorded as %+v, %v", conf, err)
+	}
+	if created, err := reserveConfiguration("acme/db", "bob", ConfigRequest{}); err != nil || created {
+		t.Errorf("acme/db is reserved again: %v, %v", created, err)
+	}
+}
+
+func TestConfDeleteHandler(t *testing.T) {
+	saved := store
+	defer func() { store = saved }()
+	SetStore(NewMemoryStore())
+	defer func(dir string) { currentDir = dir }(currentDir)
+	dir, err := ioutil.TempDir("", "conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	currentDir = dir
+	if err := os.MkdirAll(configDir("web"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	r := mux.NewRouter()
+	r.HandleFunc("/v1/configuration/{repo_name}", ConfDeleteHandler(nil))
+	serve := func(method string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, "/v1/configuration/web?force=true", nil))
+		return w
+	}
+
+	if w := serve("GET"); w.Code != 405 || strings.Count(w.Body.String(), "\n") != 1 {
+		t.Errorf("GET answered %d %q, want a single 405", w.Code, w.Body)
+	}
+	if _, err := os.Stat(configDir("web")); err != nil {
+		t.Fatalf("GET deleted the configuration: %v", err)
+	}
+
+	_, end := beginAction(context.Background(), "apply", "web", "a1")
+	if w := serve("DELETE"); w.Code != 409 {
+		t.Errorf("deleting during an action answered %d %s, want 409", w.Code, w.Body)
+	}
+	end(nil)
+	if w := serve("DELETE"); w.Code != 200 {
+		t.Errorf("deleting answered %d %s, want 200", w.Code, w.Body)
+	}
+	if _, err := os.Stat(configDir("web")); !os.IsNotExist(err) {
+		t.Errorf("the configuration is still there: %v", err)
+	}
+}
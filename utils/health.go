@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Version and Commit identify the build, set with
+// -ldflags "-X github.com/terraform-provider-ibm-api/utils.Version=..."
+var (
+	Version = "dev"
+	Commit  = ""
+)
+
+// startTime is when the server started.
+var startTime = time.Now()
+
+// checkTimeout bounds each readiness check.
+var checkTimeout = 5 * time.Second
+
+// Check -
+type Check struct {
+	Name     string `json:"name"`
+	Status   string `json:"status" description:"ok or failed"`
+	Version  string `json:"version,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// ReadyResponse -
+type ReadyResponse struct {
+	Status string  `json:"status" description:"ok when every check passed, failed otherwise"`
+	Checks []Check `json:"checks"`
+}
+
+// HealthResponse -
+type HealthResponse struct {
+	Status string `json:"status"`
+	Uptime string `json:"uptime"`
+}
+
+// VersionResponse -
+type VersionResponse struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	GoVersion string `json:"go_version"`
+	Terraform string `json:"terraform,omitempty"`
+	Git       string `json:"git,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	output, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(status)
+	w.Write(output)
+}
+
+// runCheck runs fn as the named check, fn returns the detail of the check.
+func runCheck(ctx context.Context, name string, fn func(ctx context.Context) (string, error)) Check {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	detail, err := fn(ctx)
+	c := Check{Name: name, Status: "ok", Detail: detail, Duration: time.Since(start).String()}
+	if err != nil {
+		c.Status = "failed"
+		c.Error = err.Error()
+	}
+	return c
+}
+
+// versionCheck runs fn as the named check of a binary, fn returns its version.
+func versionCheck(ctx context.Context, name string, fn func(ctx context.Context) (string, error)) Check {
+	c := runCheck(ctx, name, fn)
+	c.Version, c.Detail = c.Detail, ""
+	return c
+}
+
+// binaryVersion returns the first line printed by the version command of the binary.
+func binaryVersion(ctx context.Context, name string, args ...string) (string, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Env = append(os.Environ(), "CHECKPOINT_DISABLE=1")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %v", strings.Join(cmd.Args, " "), err)
+	}
+	return strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0]), nil
+}
+
+func terraformVersion(ctx context.Context) (string, error) {
+	return binaryVersion(ctx, "terraform", "version")
+}
+
+func gitVersion(ctx context.Context) (string, error) {
+	return binaryVersion(ctx, "git", "--version")
+}
+
+// mountDirWritable creates and removes a file in MOUNT_DIR.
+func mountDirWritable(ctx context.Context) (string, error) {
+	f, err := ioutil.TempFile(currentDir, ".readyz")
+	if err != nil {
+		return currentDir, err
+	}
+	f.Close()
+	return currentDir, os.Remove(f.Name())
+}
+
+// HealthHandler tells that the process is up.
+func HealthHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, 200, HealthResponse{Status: "ok", Uptime: time.Since(startTime).Round(time.Second).String()})
+}
+
+// ReadyHandler checks the dependencies the server needs to take requests:
+// the store, a writable MOUNT_DIR and the terraform and git binaries.
+func ReadyHandler(storeKind string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response := ReadyResponse{Status: "ok"}
+		response.Checks = []Check{
+			runCheck(r.Context(), storeKind, func(ctx context.Context) (string, error) {
+				return "", store.Ping()
+			}),
+			runCheck(r.Context(), "mount_dir", mountDirWritable),
+			versionCheck(r.Context(), "terraform", terraformVersion),
+			versionCheck(r.Context(), "git", gitVersion),
+		}
+
+		status := 200
+		for _, c := range response.Checks {
+			if c.Status != "ok" {
+				response.Status = "failed"
+				status = 503
+			}
+		}
+		writeJSON(w, status, response)
+	}
+}
+
+// VersionHandler returns the version of the server and of the binaries it runs.
+func VersionHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+	defer cancel()
+
+	response := VersionResponse{Version: Version, Commit: Commit, GoVersion: runtime.Version()}
+	response.Terraform, _ = terraformVersion(ctx)
+	response.Git, _ = gitVersion(ctx)
+	writeJSON(w, 200, response)
+}
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+//downStore is a store that can not be reached.
+type downStore struct {
+	Store
+}
+
+func (downStore) Ping() error {
+	return errors.New("connection refused")
+}
+
+func TestReadyHandler(t *testing.T) {
+	saved := store
+	defer func() { store = saved }()
+	defer func(dir string) { currentDir = dir }(currentDir)
+	defer func(d time.Duration) { checkTimeout = d }(checkTimeout)
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+
+	dir, err := ioutil.TempDir("", "readyz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	bin := filepath.Join(dir, "bin")
+	os.Mkdir(bin, 0700)
+	script := func(name, body string) {
+		ioutil.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"+body+"\n"), 0700)
+	}
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("PATH", bin)
+
+	tests := []struct {
+		name   string
+		setup  func()
+		status int
+		failed string
+	}{
+		{"ready", func() {}, 200, ""},
+		{"store down", func() { store = downStore{store} }, 503, "memory"},
+		{"mount dir missing", func() { currentDir = filepath.Join(dir, "missing") }, 503, "mount_dir"},
+		{"terraform missing", func() { os.Remove(filepath.Join(bin, "terraform")) }, 503, "terraform"},
+		{"git hangs", func() { script("git", "exec "+sleep+" 10"); checkTimeout = 100 * time.Millisecond }, 503, "git"},
+	}
+	for _, tt := range tests {
+		SetStore(NewMemoryStore())
+		currentDir = dir
+		checkTimeout = 2 * time.Second
+		script("terraform", `echo "Terraform v1.5.7"; echo "on linux_amd64"`)
+		script("git", `echo "git version 2.43.0"`)
+		tt.setup()
+
+		w := httptest.NewRecorder()
+		start := time.Now()
+		ReadyHandler("memory")(w, httptest.NewRequest("GET", "/readyz", nil))
+		if time.Since(start) > 5*time.Second {
+			t.Errorf("%s: the checks took %v", tt.name, time.Since(start))
+		}
+		var response ReadyResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("%s: %v in %s", tt.name, err, w.Body)
+		}
+		if w.Code != tt.status {
+			t.Errorf("%s: got %d, want %d: %s", tt.name, w.Code, tt.status, w.Body)
+		}
+		if want := map[bool]string{true: "ok", false: "failed"}[tt.status == 200]; response.Status != want {
+			t.Errorf("%s: status %q, want %q", tt.name, response.Status, want)
+		}
+		for _, c := range response.Checks {
+			failed := c.Name == tt.failed
+			if failed != (c.Status == "failed") || failed != (c.Error != "") {
+				t.Errorf("%s: check %+v", tt.name, c)
+			}
+		}
+		if tt.name == "ready" {
+			if len(response.Checks) != 4 || response.Checks[2].Version != "Terraform v1.5.7" || response.Checks[3].Version != "git version 2.43.0" {
+				t.Errorf("the checks are %+v", response.Checks)
+			}
+		}
+	}
+}
+
+func TestHealthHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	HealthHandler(w, httptest.NewRequest("GET", "/healthz", nil))
+	var response HealthResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil || w.Code != 200 || response.Status != "ok" {
+		t.Errorf("got %d %s, %v", w.Code, w.Body, err)
+	}
+}
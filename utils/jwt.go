@@ -0,0 +1,320 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jwksRefreshInterval is how long fetched signing keys are trusted before the
+// JWKS is fetched again. An unknown key id also triggers a fetch.
+var jwksRefreshInterval = time.Hour
+
+// jwtLeeway absorbs clock skew when checking exp and nbf.
+var jwtLeeway = time.Minute
+
+// jwtClaims are the registered claims checked when validating a bearer token.
+type jwtClaims struct {
+	Subject   string      `json:"sub"`
+	Issuer    string      `json:"iss"`
+	Audience  interface{} `json:"aud"`
+	ExpiresAt int64       `json:"exp"`
+	NotBefore int64       `json:"nbf"`
+}
+
+// jwtValidator validates RS256/RS384/RS512/ES256/ES384 tokens against the keys
+// published at a JWKS url.
+type jwtValidator struct {
+	jwksURL     string
+	issuer      string
+	audience    string
+	tenantClaim string
+
+	mu      sync.Mutex
+	keys    map[string]crypto.PublicKey
+	fetched time.Time
+	client  *http.Client
+
+	//fetching is held while the JWKS is fetched, by one request at a time.
+	fetching sync.Mutex
+}
+
+func newJWTValidator(jwksURL, issuer, audience, tenantClaim string) *jwtValidator {
+	return &jwtValidator{
+		jwksURL:     jwksURL,
+		issuer:      issuer,
+		audience:    audience,
+		tenantClaim: tenantClaim,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Validate checks the signature and claims of the token and returns its
+// subject and the tenant named by the tenant claim, if any.
+func (v *jwtValidator) Validate(token string) (string, string, error) {
+	subject, err := v.validate(token)
+	if err != nil {
+		return "", "", err
+	}
+	var claims map[string]interface{}
+	if err := decodeSegment(strings.Split(token, ".")[1], &claims); err != nil {
+		return "", "", err
+	}
+	tenant, _ := claims[v.tenantClaim].(string)
+	return subject, tenant, nil
+}
+
+func (v *jwtValidator) validate(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errors.New("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return "", err
+	}
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return "", err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", errors.New("malformed token signature")
+	}
+
+	key, err := v.key(header.Kid)
+	if err != nil {
+		return "", err
+	}
+	err = verifyJWTSignature(header.Alg, key, parts[0]+"."+parts[1], sig)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	if claims.ExpiresAt == 0 || now.After(time.Unix(claims.ExpiresAt, 0).Add(jwtLeeway)) {
+		return "", errors.New("token is expired")
+	}
+	if claims.NotBefore != 0 && now.Add(jwtLeeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return "", errors.New("token is not valid yet")
+	}
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return "", errors.New("token issuer is not trusted")
+	}
+	if v.audience != "" && !audienceContains(claims.Audience, v.audience) {
+		return "", errors.New("token audience does not match")
+	}
+	if claims.Subject == "" {
+		return "", errors.New("token has no subject")
+	}
+	return claims.Subject, nil
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return errors.New("malformed token")
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return errors.New("malformed token")
+	}
+	return nil
+}
+
+func audienceContains(aud interface{}, want string) bool {
+	switch a := aud.(type) {
+	case string:
+		return a == want
+	case []interface{}:
+		for _, v := range a {
+			if s, ok := v.(string); ok && s == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ecdsaAlgorithms are the token algorithms of the curves of the ECDSA keys.
+var ecdsaAlgorithms = map[string]string{"P-256": "ES256", "P-384": "ES384"}
+
+func verifyJWTSignature(alg string, key crypto.PublicKey, signed string, sig []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported token algorithm %q", alg)
+	}
+	digest := hashOf(hash, []byte(signed))
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return errors.New("token algorithm does not match the key")
+		}
+		if err := rsa.VerifyPKCS1v15(k, hash, digest, sig); err != nil {
+			return errors.New("invalid token signature")
+		}
+	case *ecdsa.PublicKey:
+		if alg != ecdsaAlgorithms[k.Curve.Params().Name] {
+			return errors.New("token algorithm does not match the key")
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return errors.New("invalid token signature")
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return errors.New("invalid token signature")
+		}
+	default:
+		return errors.New("unsupported signing key")
+	}
+	return nil
+}
+
+func hashOf(hash crypto.Hash, b []byte) []byte {
+	switch hash {
+	case crypto.SHA384:
+		sum := sha512.Sum384(b)
+		return sum[:]
+	case crypto.SHA512:
+		sum := sha512.Sum512(b)
+		return sum[:]
+	}
+	sum := sha256.Sum256(b)
+	return sum[:]
+}
+
+// key returns the signing key with the id, fetching the JWKS when the cached
+// keys are stale or do not know the id.
+func (v *jwtValidator) key(kid string) (crypto.PublicKey, error) {
+	if key, ok, fetched := v.cachedKey(kid); ok && time.Since(fetched) < jwksRefreshInterval {
+		return key, nil
+	}
+
+	// The JWKS is fetched without holding mu, the tokens signed with cached
+	// keys are validated meanwhile. The requests waiting for the fetch use
+	// its keys.
+	v.fetching.Lock()
+	defer v.fetching.Unlock()
+	key, ok, fetched := v.cachedKey(kid)
+	if ok && time.Since(fetched) < jwksRefreshInterval {
+		return key, nil
+	}
+	// Do not let unknown key ids hammer the JWKS endpoint.
+	if time.Since(fetched) > 10*time.Second {
+		keys, err := v.fetchKeys()
+		if err != nil {
+			// Keep using the cached key while the JWKS endpoint is unavailable.
+			if ok {
+				return key, nil
+			}
+			return nil, err
+		}
+		v.mu.Lock()
+		v.keys = keys
+		v.fetched = time.Now()
+		v.mu.Unlock()
+		key, ok = keys[kid]
+	}
+	if ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown token key id %q", kid)
+}
+
+// cachedKey returns the cached signing key with the id and when the keys were fetched.
+func (v *jwtValidator) cachedKey(kid string) (crypto.PublicKey, bool, time.Time) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	key, ok := v.keys[kid]
+	return key, ok, v.fetched
+}
+
+func (v *jwtValidator) fetchKeys() (map[string]crypto.PublicKey, error) {
+	resp, err := v.client.Get(v.jwksURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to fetch JWKS: %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&set)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %v", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		switch k.Kty {
+		case "RSA":
+			n, err1 := base64.RawURLEncoding.DecodeString(k.N)
+			e, err2 := base64.RawURLEncoding.DecodeString(k.E)
+			if err1 != nil || err2 != nil {
+				continue
+			}
+			keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		case "EC":
+			var curve elliptic.Curve
+			switch k.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			default:
+				continue
+			}
+			x, err1 := base64.RawURLEncoding.DecodeString(k.X)
+			y, err2 := base64.RawURLEncoding.DecodeString(k.Y)
+			if err1 != nil || err2 != nil {
+				continue
+			}
+			key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+			if !curve.IsOnCurve(key.X, key.Y) {
+				continue
+			}
+			keys[k.Kid] = key
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,236 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testJWKS serves the public keys of its signing keys as a JWKS.
+type testJWKS struct {
+	rsa     *rsa.PrivateKey
+	p256    *ecdsa.PrivateKey
+	p384    *ecdsa.PrivateKey
+	fetches int32
+	block   chan struct{}
+}
+
+func newTestJWKS(t *testing.T) (*testJWKS, *httptest.Server) {
+	j := &testJWKS{}
+	var err error
+	if j.rsa, err = rsa.GenerateKey(rand.Reader, 2048); err != nil {
+		t.Fatal(err)
+	}
+	if j.p256, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+		t.Fatal(err)
+	}
+	if j.p384, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader); err != nil {
+		t.Fatal(err)
+	}
+	b64 := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	set := map[string]interface{}{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "use": "sig", "n": b64(j.rsa.N.Bytes()), "e": b64(big.NewInt(int64(j.rsa.E)).Bytes())},
+		{"kty": "EC", "kid": "p256", "crv": "P-256", "x": b64(j.p256.X.Bytes()), "y": b64(j.p256.Y.Bytes())},
+		{"kty": "EC", "kid": "p384", "crv": "P-384", "x": b64(j.p384.X.Bytes()), "y": b64(j.p384.Y.Bytes())},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": b64(j.rsa.N.Bytes()), "e": "AQAB"},
+	}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&j.fetches, 1)
+		if j.block != nil {
+			<-j.block
+		}
+		json.NewEncoder(w).Encode(set)
+	}))
+	return j, srv
+}
+
+// sign returns a token with the header and claims signed with the key of the kid.
+func (j *testJWKS) sign(t *testing.T, alg, kid string, claims map[string]interface{}) string {
+	enc := func(v interface{}) string {
+		b, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	signed := enc(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"}) + "." + enc(claims)
+
+	hash := crypto.SHA256
+	switch alg[2:] {
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	}
+	digest := hashOf(hash, []byte(signed))
+
+	var sig []byte
+	switch kid {
+	case "rsa", "enc":
+		var err error
+		sig, err = rsa.SignPKCS1v15(rand.Reader, j.rsa, hash, digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+	default:
+		key := j.p256
+		if kid == "p384" {
+			key = j.p384
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		sig = make([]byte, 2*size)
+		r.FillBytes(sig[:size])
+		s.FillBytes(sig[size:])
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestJWTValidator(t *testing.T) {
+	j, srv := newTestJWKS(t)
+	defer srv.Close()
+	v := newJWTValidator(srv.URL, "https://issuer", "tf-api", "tenant")
+
+	now := time.Now().Unix()
+	claims := func(change map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{"sub": "alice", "iss": "https://issuer", "aud": "tf-api", "exp": now + 300, "tenant": "acme"}
+		for k, val := range change {
+			if val == nil {
+				delete(c, k)
+			} else {
+				c[k] = val
+			}
+		}
+		return c
+	}
+
+	// A token with the alg of its header changed keeps its signature.
+	hs256 := strings.Split(j.sign(t, "RS256", "rsa", claims(nil)), ".")
+	hs256[0] = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","kid":"rsa"}`))
+	// An ES256 signature one byte short.
+	short := strings.Split(j.sign(t, "ES256", "p256", claims(nil)), ".")
+	sig, _ := base64.RawURLEncoding.DecodeString(short[2])
+	short[2] = base64.RawURLEncoding.EncodeToString(sig[:len(sig)-1])
+
+	tests := []struct {
+		name            string
+		token           string
+		wantErr         string
+		subject, tenant string
+	}{
+		{"RS256", j.sign(t, "RS256", "rsa", claims(nil)), "", "alice", "acme"},
+		{"RS512", j.sign(t, "RS512", "rsa", claims(nil)), "", "alice", "acme"},
+		{"ES256", j.sign(t, "ES256", "p256", claims(nil)), "", "alice", "acme"},
+		{"ES384", j.sign(t, "ES384", "p384", claims(nil)), "", "alice", "acme"},
+		{"audience list", j.sign(t, "RS256", "rsa", claims(map[string]interface{}{"aud": []string{"other", "tf-api"}})), "", "alice", "acme"},
+		{"no tenant claim", j.sign(t, "RS256", "rsa", claims(map[string]interface{}{"tenant": nil})), "", "alice", ""},
+		{"within the leeway", j.sign(t, "RS256", "rsa", claims(map[string]interface{}{"exp": now - 30})), "", "alice", "acme"},
+		{"expired", j.sign(t, "RS256", "rsa", claims(map[string]interface{}{"exp": now - 120})), "expired", "", ""},
+		{"no exp", j.sign(t, "RS256", "rsa", claims(map[string]interface{}{"exp": nil})), "expired", "", ""},
+		{"not yet valid", j.sign(t, "RS256", "rsa", claims(map[string]interface{}{"nbf": now + 120})), "not valid yet", "", ""},
+		{"wrong issuer", j.sign(t, "RS256", "rsa", claims(map[string]interface{}{"iss": "https://evil"})), "issuer", "", ""},
+		{"wrong audience", j.sign(t, "RS256", "rsa", claims(map[string]interface{}{"aud": "other"})), "audience", "", ""},
+		{"no subject", j.sign(t, "RS256", "rsa", claims(map[string]interface{}{"sub": nil})), "subject", "", ""},
+		{"unknown kid", j.sign(t, "RS256", "nope", claims(nil)), "unknown token key id", "", ""},
+		{"encryption key", j.sign(t, "RS256", "enc", claims(nil)), "unknown token key id", "", ""},
+		{"no signature", strings.Join(strings.Split(j.sign(t, "RS256", "rsa", claims(nil)), ".")[:2], ".") + ".", "invalid token signature", "", ""},
+		{"HS256 with the RSA key", strings.Join(hs256, "."), "unsupported token algorithm", "", ""},
+		{"ES256 header on the RSA key", j.sign(t, "ES256", "rsa", claims(nil)), "does not match the key", "", ""},
+		{"RS256 header on an EC key", j.sign(t, "RS256", "p256", claims(nil)), "does not match the key", "", ""},
+		{"ES384 header on the P-256 key", j.sign(t, "ES384", "p256", claims(nil)), "does not match the key", "", ""},
+		{"ES256 header on the P-384 key", j.sign(t, "ES256", "p384", claims(nil)), "does not match the key", "", ""},
+		{"short ECDSA signature", strings.Join(short, "."), "invalid token signature", "", ""},
+		{"tampered claims", tamper(j.sign(t, "RS256", "rsa", claims(nil))), "invalid token signature", "", ""},
+		{"malformed", "a.b", "malformed", "", ""},
+	}
+	for _, tt := range tests {
+		sub, tenant, err := v.Validate(tt.token)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: got %q, %v, want an error with %q", tt.name, sub, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || sub != tt.subject || tenant != tt.tenant {
+			t.Errorf("%s: got %q, %q, %v, want %q, %q", tt.name, sub, tenant, err, tt.subject, tt.tenant)
+		}
+	}
+}
+
+// tamper changes the subject of the token, keeping its signature.
+func tamper(token string) string {
+	parts := strings.Split(token, ".")
+	b, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	parts[1] = base64.RawURLEncoding.EncodeToString([]byte(strings.Replace(string(b), `"alice"`, `"admin"`, 1)))
+	return strings.Join(parts, ".")
+}
+
+func TestJWTValidatorKeyCache(t *testing.T) {
+	j, srv := newTestJWKS(t)
+	defer srv.Close()
+	v := newJWTValidator(srv.URL, "", "", "tenant")
+	token := j.sign(t, "RS256", "rsa", map[string]interface{}{"sub": "alice", "exp": time.Now().Unix() + 300})
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := v.Validate(token); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt32(&j.fetches); n != 1 {
+		t.Errorf("fetched the JWKS %d times, want once", n)
+	}
+
+	// Unknown key ids do not fetch the JWKS again right away.
+	unknown := j.sign(t, "RS256", "rotated", map[string]interface{}{"sub": "alice", "exp": time.Now().Unix() + 300})
+	for i := 0; i < 3; i++ {
+		v.Validate(unknown)
+	}
+	if n := atomic.LoadInt32(&j.fetches); n != 1 {
+		t.Errorf("fetched the JWKS %d times for unknown key ids, want once", n)
+	}
+
+	// Tokens signed with cached keys are validated while the JWKS is fetched.
+	j.block = make(chan struct{})
+	v.mu.Lock()
+	v.fetched = time.Now().Add(-jwksRefreshInterval)
+	v.mu.Unlock()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		v.Validate(unknown)
+	}()
+	for atomic.LoadInt32(&j.fetches) != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	v.mu.Lock()
+	v.fetched = time.Now()
+	v.mu.Unlock()
+	done := make(chan error)
+	go func() {
+		_, _, err := v.Validate(token)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("the validation waited for the JWKS fetch")
+	}
+	close(j.block)
+	wg.Wait()
+}
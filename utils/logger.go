@@ -0,0 +1,209 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+//Records are written by log/slog. The fields of the request or action a
+//record is written for, request_id, config, action and action_id, are kept
+//in the context and added by contextHandler, so that the work is logged
+//with Log.InfoContext(ctx, ...) and the like.
+
+// logLevel is the lowest level written, set by SetLogging.
+var logLevel = new(slog.LevelVar)
+
+// Log is the logger of the server.
+var Log = newLogger(os.Stderr, "json")
+
+// newLogger returns a logger writing the records to w in format, json or text.
+func newLogger(w io.Writer, format string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: logLevel, ReplaceAttr: replaceLogAttr}
+	var h slog.Handler = slog.NewJSONHandler(w, opts)
+	if format == "text" {
+		h = slog.NewTextHandler(w, opts)
+	}
+	return slog.New(contextHandler{h})
+}
+
+// replaceLogAttr writes the time in UTC, the level in lower case and the
+// durations as text, like 1m30s.
+func replaceLogAttr(groups []string, a slog.Attr) slog.Attr {
+	switch {
+	case len(groups) == 0 && a.Key == slog.TimeKey:
+		return slog.Time(a.Key, a.Value.Time().UTC())
+	case len(groups) == 0 && a.Key == slog.LevelKey:
+		return slog.String(a.Key, strings.ToLower(a.Value.String()))
+	case a.Value.Kind() == slog.KindDuration:
+		return slog.String(a.Key, a.Value.Duration().String())
+	}
+	return a
+}
+
+// SetLogging sets the format, json or text, and the level of the records.
+// The records of the standard log package are written at info.
+func SetLogging(format, level string) error {
+	if format != "json" && format != "text" {
+		return fmt.Errorf("log format %q must be json or text", format)
+	}
+	l, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	logLevel.Set(l)
+	Log = newLogger(os.Stderr, format)
+	slog.SetDefault(Log)
+	return nil
+}
+
+var logLevels = map[string]slog.Level{
+	"debug": slog.LevelDebug,
+	"info":  slog.LevelInfo,
+	"warn":  slog.LevelWarn,
+	"error": slog.LevelError,
+}
+
+func parseLevel(level string) (slog.Level, error) {
+	l, ok := logLevels[level]
+	if !ok {
+		return 0, fmt.Errorf("log level %q must be debug, info, warn or error", level)
+	}
+	return l, nil
+}
+
+// Fatal writes an error record and exits.
+func Fatal(msg string, args ...interface{}) {
+	Log.Error(msg, args...)
+	os.Exit(1)
+}
+
+type logFieldsKey struct{}
+
+// withLogFields returns a context whose records carry the key value pairs
+// too. A key ctx already has is replaced.
+func withLogFields(ctx context.Context, kv ...interface{}) context.Context {
+	var add []slog.Attr
+	for i := 0; i+1 < len(kv); i += 2 {
+		add = append(add, slog.Any(fmt.Sprint(kv[i]), kv[i+1]))
+	}
+	fields := make([]slog.Attr, 0, len(add))
+	for _, f := range logFields(ctx) {
+		if !hasLogKey(add, f.Key) {
+			fields = append(fields, f)
+		}
+	}
+	return context.WithValue(ctx, logFieldsKey{}, append(fields, add...))
+}
+
+func logFields(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(logFieldsKey{}).([]slog.Attr)
+	return fields
+}
+
+func hasLogKey(attrs []slog.Attr, key string) bool {
+	for _, a := range attrs {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// contextHandler adds the fields of the context to the records, before their
+// own attributes, which win over a field with the same key.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := logFields(ctx)
+	if len(fields) == 0 {
+		return h.Handler.Handle(ctx, r)
+	}
+	var own []slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		own = append(own, a)
+		return true
+	})
+	out := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	for _, f := range fields {
+		if !hasLogKey(own, f.Key) {
+			out.AddAttrs(f)
+		}
+	}
+	out.AddAttrs(own...)
+	return h.Handler.Handle(ctx, out)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// RequestIDHeader carries the request ID, taken from the request when it is
+// a sane one and set on every response.
+const RequestIDHeader = "X-Request-ID"
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return fmt.Sprintf("%x", b)
+}
+
+// LoggingMiddleware gives every request an ID, returned in the X-Request-ID
+// header, and log fields carrying it and the configuration, then logs the
+// request once it is served. The probes and scrapes are logged at debug.
+func LoggingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+		spanFromContext(r.Context()).set("request.id", requestID)
+
+		route := r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if tpl, err := current.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+		ctx := withLogFields(r.Context(), "request_id", requestID)
+		if repoName := mux.Vars(r)["repo_name"]; repoName != "" {
+			ctx = withLogFields(ctx, "config", repoName)
+		}
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		status := rec.status
+		if status == 0 {
+			status = 200
+		}
+		level := slog.LevelInfo
+		if isPublicPath(r.URL.Path) && r.Method == "GET" {
+			level = slog.LevelDebug
+		}
+		Log.Log(ctx, level, "request served", "method", r.Method, "route", route, "path", r.URL.Path,
+			"status", status, "duration_ms", time.Since(start).Milliseconds(), "source_ip", sourceIP(r))
+	})
+}
@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// captureLogs writes the records of Log to the returned buffer until the test ends.
+func captureLogs(t *testing.T, format string, level slog.Level) *bytes.Buffer {
+	var buf bytes.Buffer
+	prevLog, prevLevel := Log, logLevel.Level()
+	Log = newLogger(&buf, format)
+	logLevel.Set(level)
+	t.Cleanup(func() {
+		Log = prevLog
+		logLevel.Set(prevLevel)
+	})
+	return &buf
+}
+
+func decodeRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("%q: %v", line, err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestContextFields(t *testing.T) {
+	buf := captureLogs(t, "json", slog.LevelInfo)
+
+	ctx := withLogFields(context.Background(), "request_id", "r1", "config", "repo")
+	ctx = withLogFields(ctx, "config", "acme/repo", "action", "plan", "action_id", "a1")
+	Log.InfoContext(ctx, "Action started", "duration", 90*time.Second, "error", errors.New("boom"))
+	Log.ErrorContext(ctx, "Failed to open the action logs", "action_id", "a2")
+	Log.DebugContext(ctx, "Running git")
+	Log.Info("Without fields")
+
+	records := decodeRecords(t, buf)
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3:\n%s", len(records), buf)
+	}
+	want := map[string]interface{}{
+		"level": "info", "msg": "Action started", "request_id": "r1", "config": "acme/repo",
+		"action": "plan", "action_id": "a1", "duration": "1m30s", "error": "boom",
+	}
+	for k, v := range want {
+		if records[0][k] != v {
+			t.Errorf("%s = %v, want %v", k, records[0][k], v)
+		}
+	}
+	if ts, _ := records[0]["time"].(string); !strings.HasSuffix(ts, "Z") {
+		t.Errorf("time %q is not UTC", ts)
+	}
+	// The attributes of the record win over the fields of the context.
+	if records[1]["level"] != "error" || records[1]["action_id"] != "a2" || records[1]["request_id"] != "r1" {
+		t.Errorf("got %v", records[1])
+	}
+	if _, ok := records[2]["request_id"]; ok || records[2]["msg"] != "Without fields" {
+		t.Errorf("got %v", records[2])
+	}
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	buf := captureLogs(t, "text", slog.LevelDebug)
+
+	r := mux.NewRouter()
+	r.Use(LoggingMiddleware)
+	r.HandleFunc("/v1/configuration/{repo_name}", func(w http.ResponseWriter, r *http.Request) {
+		Log.InfoContext(r.Context(), "Creating a configuration")
+		w.WriteHeader(202)
+	})
+
+	req := httptest.NewRequest("POST", "/v1/configuration/repo", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get(RequestIDHeader); got != "abc-123" {
+		t.Errorf("%s = %q, want abc-123", RequestIDHeader, got)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d records, want 2:\n%s", len(lines), buf)
+	}
+	for _, want := range []string{"level=info", `msg="Creating a configuration"`, "request_id=abc-123", "config=repo"} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("%q does not contain %s", lines[0], want)
+		}
+	}
+	for _, want := range []string{`msg="request served"`, "request_id=abc-123", "route=/v1/configuration/{repo_name}", "status=202"} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("%q does not contain %s", lines[1], want)
+		}
+	}
+
+	// A request ID that could forge log lines is replaced.
+	buf.Reset()
+	req = httptest.NewRequest("POST", "/v1/configuration/repo", nil)
+	req.Header.Set(RequestIDHeader, "x\nlevel=error")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if got := w.Header().Get(RequestIDHeader); !validRequestID.MatchString(got) || strings.Contains(buf.String(), "level=error") {
+		t.Errorf("request id %q, logs:\n%s", got, buf)
+	}
+}
+
+func TestSetLogging(t *testing.T) {
+	prev := Log
+	defer func() { Log = prev }()
+	for _, tt := range []struct {
+		format, level string
+		ok            bool
+	}{
+		{"json", "debug", true},
+		{"text", "error", true},
+		{"xml", "info", false},
+		{"json", "trace", false},
+		{"json", "INFO", false},
+	} {
+		if err := SetLogging(tt.format, tt.level); (err == nil) != tt.ok {
+			t.Errorf("SetLogging(%q, %q) = %v, want ok %v", tt.format, tt.level, err, tt.ok)
+		}
+	}
+	SetLogging("json", "info")
+}
@@ -0,0 +1,231 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// The metrics are served at /metrics in the Prometheus text format.
+var (
+	httpRequests = newCounterVec("terraform_api_http_requests_total",
+		"HTTP requests served, by route, method and status code.", "route", "method", "code")
+	httpDuration = newHistogramVec("terraform_api_http_request_duration_seconds",
+		"Time taken to serve HTTP requests, by route and method.",
+		[]float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}, "route", "method")
+	actionsTotal = newCounterVec("terraform_api_actions_total",
+		"Finished actions, by action and final status.", "action", "status")
+	actionDuration = newHistogramVec("terraform_api_action_duration_seconds",
+		"Time taken by finished actions, from getting a worker to the end, by action and final status.",
+		[]float64{5, 15, 30, 60, 120, 300, 600, 1200, 1800, 3600}, "action", "status")
+	actionsQueued      = newGauge("terraform_api_actions_queued", "Actions waiting for a free worker, see ACTION_WORKERS.")
+	actionsRunning     = newGauge("terraform_api_actions_running", "Actions running.")
+	terraformProcesses = newGauge("terraform_api_terraform_processes", "Terraform processes running.")
+	slackFailures      = newCounterVec("terraform_api_slack_failures_total",
+		"Messages that could not be posted to slack, by method (webhook or api).", "method")
+)
+
+// metric is written out in the Prometheus text format.
+type metric interface {
+	write(w *bufio.Writer)
+}
+
+// registry lists the metrics in the order they are served.
+var registry []metric
+
+func register(m metric) {
+	registry = append(registry, m)
+}
+
+func writeHeader(w *bufio.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatLabels formats the label pairs as {name="value",...}.
+func formatLabels(names, values []string, extra ...string) string {
+	pairs := make([]string, 0, len(names)+1)
+	for i, n := range names {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, n, labelEscaper.Replace(values[i])))
+	}
+	if len(extra) == 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extra[0], extra[1]))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// labelKey joins label values into a map key.
+func labelKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+// counterVec is a counter for each set of label values.
+type counterVec struct {
+	name, help string
+	labels     []string
+	mu         sync.Mutex
+	values     map[string]float64
+}
+
+func newCounterVec(name, help string, labels ...string) *counterVec {
+	c := &counterVec{name: name, help: help, labels: labels, values: make(map[string]float64)}
+	register(c)
+	return c
+}
+
+// inc adds one to the counter of the label values, given in the order of the labels.
+func (c *counterVec) inc(values ...string) {
+	c.mu.Lock()
+	c.values[labelKey(values)]++
+	c.mu.Unlock()
+}
+
+func (c *counterVec) write(w *bufio.Writer) {
+	writeHeader(w, c.name, c.help, "counter")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys := make([]string, 0, len(c.values))
+	for k := range c.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, formatLabels(c.labels, strings.Split(k, "\xff")), formatFloat(c.values[k]))
+	}
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// histogramVec is a histogram for each set of label values.
+type histogramVec struct {
+	name, help string
+	labels     []string
+	buckets    []float64
+	mu         sync.Mutex
+	values     map[string]*histogram
+}
+
+func newHistogramVec(name, help string, buckets []float64, labels ...string) *histogramVec {
+	h := &histogramVec{name: name, help: help, labels: labels, buckets: buckets, values: make(map[string]*histogram)}
+	register(h)
+	return h
+}
+
+// observe records v in the histogram of the label values.
+func (h *histogramVec) observe(v float64, values ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	k := labelKey(values)
+	hist, ok := h.values[k]
+	if !ok {
+		hist = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.values[k] = hist
+	}
+	for i, b := range h.buckets {
+		if v <= b {
+			hist.counts[i]++
+		}
+	}
+	hist.count++
+	hist.sum += v
+}
+
+func (h *histogramVec) write(w *bufio.Writer) {
+	writeHeader(w, h.name, h.help, "histogram")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	keys := make([]string, 0, len(h.values))
+	for k := range h.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		values := strings.Split(k, "\xff")
+		hist := h.values[k]
+		for i, b := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, values, "le", formatFloat(b)), hist.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, values, "le", "+Inf"), hist.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, formatLabels(h.labels, values), formatFloat(hist.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(h.labels, values), hist.count)
+	}
+}
+
+// gauge is a value going up and down.
+type gauge struct {
+	name, help string
+	value      int64
+}
+
+func newGauge(name, help string) *gauge {
+	g := &gauge{name: name, help: help}
+	register(g)
+	return g
+}
+
+func (g *gauge) add(n int64) {
+	atomic.AddInt64(&g.value, n)
+}
+
+func (g *gauge) write(w *bufio.Writer) {
+	writeHeader(w, g.name, g.help, "gauge")
+	fmt.Fprintf(w, "%s %d\n", g.name, atomic.LoadInt64(&g.value))
+}
+
+// MetricsMiddleware counts the requests and their duration by route path
+// template, so that the metrics do not grow with configuration names.
+func MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if tpl, err := current.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		status := rec.status
+		if status == 0 {
+			status = 200
+		}
+		httpRequests.inc(route, r.Method, strconv.Itoa(status))
+		httpDuration.observe(time.Since(start).Seconds(), route, r.Method)
+	})
+}
+
+// MetricsHandler serves the metrics in the Prometheus text format.
+func MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("content-type", "text/plain; version=0.0.4; charset=utf-8")
+	bw := bufio.NewWriter(w)
+	for _, m := range registry {
+		m.write(bw)
+	}
+	bw.Flush()
+}
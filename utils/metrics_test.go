@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+//exposition returns the metric in the Prometheus text format.
+func exposition(m metric) string {
+	var b bytes.Buffer
+	w := bufio.NewWriter(&b)
+	m.write(w)
+	w.Flush()
+	return b.String()
+}
+
+func TestCounterVec(t *testing.T) {
+	c := &counterVec{name: "test_total", help: "Test counter.", labels: []string{"route", "code"}, values: map[string]float64{}}
+	c.inc("/b", "200")
+	c.inc("/a", "500")
+	c.inc("/a", "500")
+	c.inc(`say "hi"\`+"\n", "200")
+
+	want := `# HELP test_total Test counter.
+# TYPE test_total counter
+test_total{route="/a",code="500"} 2
+test_total{route="/b",code="200"} 1
+test_total{route="say \"hi\"\\\n",code="200"} 1
+`
+	if got := exposition(c); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHistogramVec(t *testing.T) {
+	h := &histogramVec{name: "test_seconds", help: "Test histogram.", labels: []string{"action"}, buckets: []float64{.5, 1, 10}, values: map[string]*histogram{}}
+	for _, v := range []float64{0.25, 0.5, 3, 3, 20} {
+		h.observe(v, "plan")
+	}
+	h.observe(1, "apply")
+
+	// The buckets are cumulative, +Inf counts every observation.
+	want := `# HELP test_seconds Test histogram.
+# TYPE test_seconds histogram
+test_seconds_bucket{action="apply",le="0.5"} 0
+test_seconds_bucket{action="apply",le="1"} 1
+test_seconds_bucket{action="apply",le="10"} 1
+test_seconds_bucket{action="apply",le="+Inf"} 1
+test_seconds_sum{action="apply"} 1
+test_seconds_count{action="apply"} 1
+test_seconds_bucket{action="plan",le="0.5"} 2
+test_seconds_bucket{action="plan",le="1"} 2
+test_seconds_bucket{action="plan",le="10"} 4
+test_seconds_bucket{action="plan",le="+Inf"} 5
+test_seconds_sum{action="plan"} 26.75
+test_seconds_count{action="plan"} 5
+`
+	if got := exposition(h); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGauge(t *testing.T) {
+	g := &gauge{name: "test_running", help: "Test gauge."}
+	g.add(3)
+	g.add(-1)
+	want := "# HELP test_running Test gauge.\n# TYPE test_running gauge\ntest_running 2\n"
+	if got := exposition(g); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	defer func(requests map[string]float64, durations map[string]*histogram) {
+		httpRequests.values, httpDuration.values = requests, durations
+	}(httpRequests.values, httpDuration.values)
+	httpRequests.values, httpDuration.values = map[string]float64{}, map[string]*histogram{}
+
+	r := mux.NewRouter()
+	r.Use(MetricsMiddleware)
+	r.HandleFunc("/v1/configuration/{repo_name}", func(w http.ResponseWriter, r *http.Request) {
+		if mux.Vars(r)["repo_name"] == "missing" {
+			http.Error(w, "not found", 404)
+		}
+	})
+	r.HandleFunc("/metrics", MetricsHandler)
+	for _, name := range []string{"web", "db", "missing"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/v1/configuration/"+name, nil))
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := w.Header().Get("content-type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("content-type %q", ct)
+	}
+	body := w.Body.String()
+	// The requests are counted by route template, not by configuration name.
+	for _, want := range []string{
+		`terraform_api_http_requests_total{route="/v1/configuration/{repo_name}",method="GET",code="200"} 2`,
+		`terraform_api_http_requests_total{route="/v1/configuration/{repo_name}",method="GET",code="404"} 1`,
+		`terraform_api_http_request_duration_seconds_count{route="/v1/configuration/{repo_name}",method="GET"} 3`,
+		"# TYPE terraform_api_actions_running gauge",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("the metrics do not have %s", want)
+		}
+	}
+	if strings.Contains(body, "/v1/configuration/web") {
+		t.Error("the metrics have a configuration name")
+	}
+}
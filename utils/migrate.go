@@ -0,0 +1,91 @@
+package utils
+
+import (
+	mgo "gopkg.in/mgo.v2"
+)
+
+// MigrationCounts are the records MigrateMongo copied.
+type MigrationCounts struct {
+	Actions        int
+	Configurations int
+	Grants         int
+	APIKeys        int
+}
+
+// MigrateMongo copies the actions, configurations, grants and API keys kept
+// in MongoDB into the store. It is meant to run while the server is stopped,
+// and can be run again: actions and API keys already in the store are left as
+// they are.
+func MigrateMongo(s *mgo.Session, dst Store) (n MigrationCounts, err error) {
+	session := s.Copy()
+	defer session.Close()
+
+	var a ActionResponse
+	iter := session.DB(dbName).C("actionDetails").Find(nil).Iter()
+	for iter.Next(&a) {
+		if err = dst.InsertAction(a); err != nil {
+			iter.Close()
+			return
+		}
+		n.Actions++
+		a = ActionResponse{}
+	}
+	if err = iter.Close(); err != nil {
+		return
+	}
+
+	var c Configuration
+	iter = session.DB(dbName).C("configurations").Find(nil).Iter()
+	for iter.Next(&c) {
+		if err = dst.SaveConfiguration(c); err != nil {
+			iter.Close()
+			return
+		}
+		n.Configurations++
+		c = Configuration{}
+	}
+	if err = iter.Close(); err != nil {
+		return
+	}
+
+	var g Grant
+	iter = session.DB(dbName).C("grants").Find(nil).Iter()
+	for iter.Next(&g) {
+		if err = dst.SaveGrant(g); err != nil {
+			iter.Close()
+			return
+		}
+		n.Grants++
+		g = Grant{}
+	}
+	if err = iter.Close(); err != nil {
+		return
+	}
+
+	var k APIKey
+	iter = session.DB(dbName).C("apiKeys").Find(nil).Iter()
+	for iter.Next(&k) {
+		if _, err = dst.FindAPIKey(k.KeyID); err == nil {
+			k = APIKey{}
+			continue
+		}
+		if err != ErrNotFound {
+			iter.Close()
+			return
+		}
+		if err = dst.InsertAPIKey(k); err != nil {
+			iter.Close()
+			return
+		}
+		if k.Revoked {
+			if err = dst.RevokeAPIKey(k.KeyID, k.RevokedAt); err != nil {
+				iter.Close()
+				return
+			}
+		}
+		n.APIKeys++
+		k = APIKey{}
+	}
+	err = iter.Close()
+	return
+}
@@ -0,0 +1,454 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Notifier delivers an action notice to one notification channel.
+type Notifier interface {
+	Notify(n ActionNotice) error
+}
+
+// SMTPSettings is the mail server used by the email channels.
+type SMTPSettings struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
+
+// smtpSettings are set by Configure.
+var smtpSettings SMTPSettings
+
+var notifyHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// SubscriptionRules -
+type SubscriptionRules struct {
+	OnlyFailures  bool     `json:"only_failures,omitempty" description:"Only notify about failed actions and detected drift"`
+	Actions       []string `json:"actions,omitempty" description:"Only notify about these actions, e.g. apply, destroy or drift. Defaults to all"`
+	NotifyStarted bool     `json:"notify_started,omitempty" description:"Also notify when an action starts"`
+}
+
+// ChannelRequest -
+type ChannelRequest struct {
+	Type       string   `json:"type,required" description:"slack, teams or email"`
+	URL        string   `json:"url,omitempty" description:"Incoming webhook url of the slack or teams channel"`
+	Recipients []string `json:"recipients,omitempty" description:"Email addresses of the email channel"`
+	SubscriptionRules
+}
+
+// NotificationChannel -
+type NotificationChannel struct {
+	ChannelID         string   `json:"channel_id"`
+	ConfigName        string   `json:"id" description:"Name of the configuration"`
+	Type              string   `json:"type"`
+	URL               string   `json:"-"`
+	Recipients        []string `json:"recipients,omitempty"`
+	Timestamp         string   `json:"timestamp"`
+	SubscriptionRules `bson:",inline"`
+}
+
+// notifiableActions are the actions a subscription can filter on.
+var notifiableActions = map[string]bool{"plan": true, "apply": true, "destroy": true, "show": true, "drift": true}
+
+// matches tells whether the subscription wants the notice.
+func (r SubscriptionRules) matches(n ActionNotice) bool {
+	if n.Status == "In-Progress" && !r.NotifyStarted {
+		return false
+	}
+	if r.OnlyFailures && !n.failed() {
+		return false
+	}
+	if len(r.Actions) == 0 {
+		return true
+	}
+	for _, a := range r.Actions {
+		if a == n.Action {
+			return true
+		}
+	}
+	return false
+}
+
+// notifier returns the Notifier delivering to the channel.
+func (c NotificationChannel) notifier() Notifier {
+	switch c.Type {
+	case "slack":
+		return slackNotifier{webhook: c.URL}
+	case "teams":
+		return teamsNotifier{webhookURL: c.URL}
+	case "email":
+		return emailNotifier{settings: smtpSettings, to: c.Recipients}
+	}
+	return nil
+}
+
+type slackNotifier struct {
+	webhook string
+}
+
+// Notify posts to the channel's own incoming webhook.
+func (n slackNotifier) Notify(notice ActionNotice) error {
+	return ComposeSlackMessage(notice).postToSlackWebhook(n.webhook)
+}
+
+type teamsNotifier struct {
+	webhookURL string
+}
+
+// Notify posts a MessageCard to the Teams incoming webhook.
+func (n teamsNotifier) Notify(notice ActionNotice) error {
+	color := "2EB886"
+	if notice.failed() {
+		color = "D40E0D"
+	}
+
+	facts := []map[string]string{
+		{"name": "Configuration", "value": notice.ConfigName},
+		{"name": "Action", "value": notice.Action},
+		{"name": "Status", "value": notice.Status},
+	}
+	if notice.GitRef != "" {
+		facts = append(facts, map[string]string{"name": "Git ref", "value": notice.GitRef})
+	}
+	if notice.Summary != "" {
+		facts = append(facts, map[string]string{"name": "Changes", "value": notice.Summary})
+	}
+	if notice.Duration > 0 {
+		facts = append(facts, map[string]string{"name": "Duration", "value": notice.Duration.Round(time.Second).String()})
+	}
+	section := map[string]interface{}{"facts": facts}
+	if notice.StderrTail != "" {
+		section["text"] = "<pre>" + html.EscapeString(notice.StderrTail) + "</pre>"
+	}
+
+	card := map[string]interface{}{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"summary":    noticeTitle(notice),
+		"themeColor": color,
+		"title":      noticeTitle(notice),
+		"sections":   []interface{}{section},
+		"potentialAction": []interface{}{
+			openURI("See Output Logs", notice.OutputURL),
+			openURI("See Error Logs", notice.ErrorURL),
+		},
+	}
+
+	b, err := json.Marshal(card)
+	if err != nil {
+		return err
+	}
+	resp, err := notifyHTTPClient.Post(n.webhookURL, "application/json", bytes.NewBuffer(b))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("teams webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+func openURI(name, uri string) map[string]interface{} {
+	return map[string]interface{}{
+		"@type":   "OpenUri",
+		"name":    name,
+		"targets": []map[string]string{{"os": "default", "uri": uri}},
+	}
+}
+
+type emailNotifier struct {
+	settings SMTPSettings
+	to       []string
+}
+
+// Notify sends a plain text mail through the configured SMTP server.
+func (n emailNotifier) Notify(notice ActionNotice) error {
+	if n.settings.Host == "" {
+		return fmt.Errorf("SMTP_HOST is not set")
+	}
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "Configuration: %s\r\n", notice.ConfigName)
+	fmt.Fprintf(&body, "Action:        %s %s\r\n", notice.Action, notice.ActionID)
+	fmt.Fprintf(&body, "Status:        %s\r\n", notice.Status)
+	if notice.GitRef != "" {
+		fmt.Fprintf(&body, "Git ref:       %s\r\n", notice.GitRef)
+	}
+	if notice.Summary != "" {
+		fmt.Fprintf(&body, "Changes:       %s\r\n", notice.Summary)
+	}
+	if notice.Duration > 0 {
+		fmt.Fprintf(&body, "Duration:      %s\r\n", notice.Duration.Round(time.Second))
+	}
+	fmt.Fprintf(&body, "\r\nOutput logs: %s\r\nError logs:  %s\r\n", notice.OutputURL, notice.ErrorURL)
+	if notice.StderrTail != "" {
+		fmt.Fprintf(&body, "\r\n%s\r\n", strings.Replace(notice.StderrTail, "\n", "\r\n", -1))
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.settings.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", noticeTitle(notice))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	body.WriteTo(&msg)
+
+	var auth smtp.Auth
+	if n.settings.Username != "" {
+		auth = smtp.PlainAuth("", n.settings.Username, n.settings.Password, n.settings.Host)
+	}
+	return smtp.SendMail(net.JoinHostPort(n.settings.Host, n.settings.Port), auth, n.settings.From, n.to, msg.Bytes())
+}
+
+func noticeTitle(notice ActionNotice) string {
+	return fmt.Sprintf("%s %s for %s : %s", notice.Action, notice.ActionID, notice.ConfigName, notice.Status)
+}
+
+// notifyChannels sends the notice to the notification channels of its
+// configuration whose subscription rules match it.
+func notifyChannels(s *mgo.Session, notice ActionNotice) {
+	if s == nil {
+		return
+	}
+	session := s.Copy()
+	defer session.Close()
+
+	var channels []NotificationChannel
+	c := session.DB(dbName).C("notificationChannels")
+	err := c.Find(bson.M{"configname": notice.ConfigName}).All(&channels)
+	if err != nil {
+		Log.Error("Failed to load the notification channels", "config", notice.ConfigName, "action_id", notice.ActionID, "error", err)
+		return
+	}
+	go deliverNotice(channels, notice)
+}
+
+// deliverNotice sends the notice to the channels whose subscription rules
+// match it, at once, and returns when they are all done.
+func deliverNotice(channels []NotificationChannel, notice ActionNotice) {
+	var wg sync.WaitGroup
+	for _, ch := range channels {
+		n := ch.notifier()
+		if n == nil || !ch.matches(notice) {
+			continue
+		}
+		wg.Add(1)
+		go func(ch NotificationChannel, n Notifier) {
+			defer wg.Done()
+			err := n.Notify(notice)
+			if err != nil {
+				Log.Error("Failed to notify the channel", "config", notice.ConfigName, "action_id", notice.ActionID,
+					"channel_type", ch.Type, "channel_id", ch.ChannelID, "error", err)
+			}
+		}(ch, n)
+	}
+	wg.Wait()
+}
+
+// ChannelCreateHandler handles request to add a notification channel to the configuration.
+func ChannelCreateHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session := s.Copy()
+		defer session.Close()
+
+		repoName := configOf(r)
+
+		b, err := ioutil.ReadAll(r.Body)
+		defer r.Body.Close()
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+
+		var msg ChannelRequest
+		err = json.Unmarshal(b, &msg)
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		err = msg.validate()
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+
+		channel := NotificationChannel{
+			ChannelID:         newActionID(),
+			ConfigName:        repoName,
+			Type:              msg.Type,
+			URL:               msg.URL,
+			Recipients:        msg.Recipients,
+			Timestamp:         time.Now().Format("20060102150405"),
+			SubscriptionRules: msg.SubscriptionRules,
+		}
+
+		c := session.DB(dbName).C("notificationChannels")
+		err = c.Insert(channel)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+
+		output, err := json.MarshalIndent(channel, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(201)
+		w.Write(output)
+	}
+}
+
+func (msg ChannelRequest) validate() error {
+	switch msg.Type {
+	case "slack", "teams":
+		u, err := url.Parse(msg.URL)
+		if err != nil || u.Scheme != "https" && u.Scheme != "http" || u.Host == "" {
+			return fmt.Errorf("Invalid url %q", msg.URL)
+		}
+	case "email":
+		if len(msg.Recipients) == 0 {
+			return fmt.Errorf("An email channel needs at least one recipient")
+		}
+		for _, to := range msg.Recipients {
+			if _, err := mail.ParseAddress(to); err != nil {
+				return fmt.Errorf("Invalid recipient %q", to)
+			}
+		}
+	default:
+		return fmt.Errorf("Invalid channel type %q, it must be slack, teams or email", msg.Type)
+	}
+	for _, a := range msg.Actions {
+		if !notifiableActions[a] {
+			return fmt.Errorf("Invalid action %q in the subscription", a)
+		}
+	}
+	return nil
+}
+
+// ChannelListHandler handles request to list the notification channels of the configuration.
+func ChannelListHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session := s.Copy()
+		defer session.Close()
+
+		repoName := configOf(r)
+
+		channels := []NotificationChannel{}
+		c := session.DB(dbName).C("notificationChannels")
+		err := c.Find(bson.M{"configname": repoName}).All(&channels)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+
+		output, err := json.MarshalIndent(channels, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		w.Header().Set("content-type", "application/json")
+		w.Write(output)
+	}
+}
+
+// ChannelUpdateHandler handles request to change a notification channel or its subscription rules.
+func ChannelUpdateHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session := s.Copy()
+		defer session.Close()
+
+		vars := mux.Vars(r)
+
+		b, err := ioutil.ReadAll(r.Body)
+		defer r.Body.Close()
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+
+		var msg ChannelRequest
+		err = json.Unmarshal(b, &msg)
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		err = msg.validate()
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+
+		var channel NotificationChannel
+		c := session.DB(dbName).C("notificationChannels")
+		selector := bson.M{"configname": configOf(r), "channelid": vars["channel_id"]}
+		err = c.Find(selector).One(&channel)
+		if err == mgo.ErrNotFound {
+			http.Error(w, "There is no notification channel for this request.", 404)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+
+		channel.Type = msg.Type
+		channel.URL = msg.URL
+		channel.Recipients = msg.Recipients
+		channel.SubscriptionRules = msg.SubscriptionRules
+		err = c.Update(selector, channel)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+
+		output, err := json.MarshalIndent(channel, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		w.Header().Set("content-type", "application/json")
+		w.Write(output)
+	}
+}
+
+// ChannelDeleteHandler handles request to remove a notification channel.
+func ChannelDeleteHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session := s.Copy()
+		defer session.Close()
+
+		vars := mux.Vars(r)
+
+		c := session.DB(dbName).C("notificationChannels")
+		err := c.Remove(bson.M{"configname": configOf(r), "channelid": vars["channel_id"]})
+		if err == mgo.ErrNotFound {
+			http.Error(w, "There is no notification channel for this request.", 404)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+	}
+}
@@ -0,0 +1,459 @@
+package utils
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSubscriptionRulesMatch(t *testing.T) {
+	tests := []struct {
+		rules  SubscriptionRules
+		action string
+		status string
+		want   bool
+	}{
+		{SubscriptionRules{}, "apply", "Completed", true},
+		{SubscriptionRules{}, "apply", "Failed", true},
+		{SubscriptionRules{}, "apply", "In-Progress", false},
+		{SubscriptionRules{NotifyStarted: true}, "apply", "In-Progress", true},
+		{SubscriptionRules{OnlyFailures: true}, "apply", "Completed", false},
+		{SubscriptionRules{OnlyFailures: true}, "drift", driftDetected, true},
+		{SubscriptionRules{OnlyFailures: true}, "drift", driftCleared, false},
+		{SubscriptionRules{OnlyFailures: true, Actions: []string{"drift"}}, "drift", driftDetected, true},
+		{SubscriptionRules{OnlyFailures: true, Actions: []string{"drift"}}, "drift", "Failed", true},
+		{SubscriptionRules{OnlyFailures: true, Actions: []string{"drift"}}, "apply", "Failed", false},
+		{SubscriptionRules{Actions: []string{"drift"}}, "drift", driftCleared, true},
+		{SubscriptionRules{OnlyFailures: true, NotifyStarted: true}, "apply", "In-Progress", false},
+		{SubscriptionRules{Actions: []string{"apply", "destroy"}}, "destroy", "Completed", true},
+		{SubscriptionRules{Actions: []string{"apply", "destroy"}}, "plan", "Completed", false},
+		{SubscriptionRules{Actions: []string{"apply"}, OnlyFailures: true}, "apply", "Failed", true},
+	}
+	for _, tt := range tests {
+		got := tt.rules.matches(ActionNotice{Action: tt.action, Status: tt.status})
+		if got != tt.want {
+			t.Errorf("%+v matches %s %s = %v, want %v", tt.rules, tt.action, tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestDriftNotice(t *testing.T) {
+	tests := []struct {
+		name      string
+		last      DriftStatus
+		status    string
+		resources []string
+		want      string
+	}{
+		{"started", DriftStatus{}, "In-Progress", nil, ""},
+		{"drift appears", DriftStatus{}, "Completed", []string{"ibm_is_vpc.vpc"}, driftDetected},
+		{"drift stays", DriftStatus{Drifted: true}, "Completed", []string{"ibm_is_vpc.vpc"}, ""},
+		{"drift clears", DriftStatus{Drifted: true}, "Completed", nil, driftCleared},
+		{"no drift", DriftStatus{}, "Completed", nil, ""},
+		{"check fails", DriftStatus{Drifted: true}, "Failed", nil, "Failed"},
+		{"check fails again", DriftStatus{Error: "exit status 1"}, "Failed", nil, ""},
+		{"check recovers without drift", DriftStatus{Error: "exit status 1"}, "Completed", nil, ""},
+	}
+	for _, tt := range tests {
+		notice := ActionNotice{ConfigName: "infra", Action: "drift", ActionID: "d1", Status: tt.status, Summary: "No changes."}
+		posted := driftNotice(tt.last, tt.resources, &notice)
+		if posted != (tt.want != "") || posted && notice.Status != tt.want {
+			t.Errorf("%s: posted %v with status %q, want %q", tt.name, posted, notice.Status, tt.want)
+		}
+		// Only failures still hears about the drift and the failed checks.
+		only := SubscriptionRules{OnlyFailures: true, Actions: []string{"drift"}}
+		if posted && only.matches(notice) != (tt.want != driftCleared) {
+			t.Errorf("%s: %s matched by only failures = %v", tt.name, notice.Status, only.matches(notice))
+		}
+	}
+	notice := ActionNotice{Status: "Completed"}
+	driftNotice(DriftStatus{}, []string{"ibm_is_vpc.vpc", "ibm_is_subnet.subnet"}, &notice)
+	if notice.Summary != "2 resource(s) changed outside terraform: ibm_is_vpc.vpc, ibm_is_subnet.subnet" {
+		t.Errorf("got summary %q", notice.Summary)
+	}
+}
+
+// smtpStub is an SMTP server accepting every mail, without extensions.
+type smtpStub struct {
+	net.Listener
+	mu    sync.Mutex
+	mails []smtpMail
+}
+
+type smtpMail struct {
+	from string
+	to   []string
+	data string
+}
+
+func newSMTPStub(t *testing.T) *smtpStub {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &smtpStub{Listener: l}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpStub) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ESMTP")
+	var mail smtpMail
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch {
+		case cmd == "EHLO" || cmd == "HELO":
+			reply("250 localhost")
+		case strings.HasPrefix(strings.ToUpper(line), "MAIL FROM:"):
+			mail = smtpMail{from: strings.Trim(line[len("MAIL FROM:"):], "<>")}
+			reply("250 OK")
+		case strings.HasPrefix(strings.ToUpper(line), "RCPT TO:"):
+			mail.to = append(mail.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			mail.data = data.String()
+			s.mu.Lock()
+			s.mails = append(s.mails, mail)
+			s.mu.Unlock()
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestNotifiers(t *testing.T) {
+	var mu sync.Mutex
+	posts := map[string][]byte{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		posts[r.URL.Path] = b
+		mu.Unlock()
+		if strings.HasPrefix(r.URL.Path, "/gone") {
+			w.WriteHeader(410)
+		}
+	}))
+	defer srv.Close()
+
+	mailer := newSMTPStub(t)
+	defer mailer.Close()
+	host, port, _ := net.SplitHostPort(mailer.Addr().String())
+	defer func(s SMTPSettings) { smtpSettings = s }(smtpSettings)
+	smtpSettings = SMTPSettings{Host: host, Port: port, From: "terraform@example.com"}
+
+	notice := ActionNotice{
+		ConfigName: "infra",
+		Action:     "apply",
+		ActionID:   "a1",
+		Status:     "Failed",
+		GitRef:     "master@abc123",
+		Summary:    "2 to add, 0 to change, 1 to destroy",
+		Duration:   90 * time.Second,
+		StderrTail: "Error: <instance> is locked",
+		OutputURL:  "http://tf/v1/configuration/infra/apply/a1.out",
+		ErrorURL:   "http://tf/v1/configuration/infra/apply/a1.err",
+	}
+
+	slack := NotificationChannel{Type: "slack", URL: srv.URL + "/slack"}
+	if err := slack.notifier().Notify(notice); err != nil {
+		t.Fatal(err)
+	}
+	var message SlackMessage
+	if err := json.Unmarshal(posts["/slack"], &message); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"master@abc123", "1m30s", "is locked", notice.OutputURL, notice.ErrorURL} {
+		if !strings.Contains(string(posts["/slack"]), want) {
+			t.Errorf("slack message %s does not contain %q", posts["/slack"], want)
+		}
+	}
+	if message.Text != "Status for apply a1 : Failed" || message.ThreadTS != "" {
+		t.Errorf("slack message %+v", message)
+	}
+
+	teams := NotificationChannel{Type: "teams", URL: srv.URL + "/teams"}
+	if err := teams.notifier().Notify(notice); err != nil {
+		t.Fatal(err)
+	}
+	var card map[string]interface{}
+	if err := json.Unmarshal(posts["/teams"], &card); err != nil {
+		t.Fatal(err)
+	}
+	if card["@type"] != "MessageCard" || card["themeColor"] != "D40E0D" || card["title"] != "apply a1 for infra : Failed" {
+		t.Errorf("teams card %v", card)
+	}
+	// The stderr tail is html in a card.
+	if !strings.Contains(string(posts["/teams"]), "Error: \\u0026lt;instance\\u0026gt; is locked") {
+		t.Errorf("teams card %s does not escape the stderr tail", posts["/teams"])
+	}
+
+	email := NotificationChannel{Type: "email", Recipients: []string{"ops@example.com", "dev@example.com"}}
+	if err := email.notifier().Notify(notice); err != nil {
+		t.Fatal(err)
+	}
+	mailer.mu.Lock()
+	if len(mailer.mails) != 1 {
+		t.Fatalf("got %d mails, want 1", len(mailer.mails))
+	}
+	m := mailer.mails[0]
+	mailer.mu.Unlock()
+	if m.from != "terraform@example.com" || strings.Join(m.to, ",") != "ops@example.com,dev@example.com" {
+		t.Errorf("mail from %s to %v", m.from, m.to)
+	}
+	for _, want := range []string{"Subject: apply a1 for infra : Failed\r\n", "To: ops@example.com, dev@example.com\r\n",
+		"Git ref:       master@abc123\r\n", "Duration:      1m30s\r\n", "\r\nError: <instance> is locked\r\n"} {
+		if !strings.Contains(m.data, want) {
+			t.Errorf("mail does not contain %q:\n%s", want, m.data)
+		}
+	}
+
+	// Refused deliveries are reported.
+	for _, ch := range []NotificationChannel{
+		{Type: "slack", URL: srv.URL + "/gone/slack"},
+		{Type: "teams", URL: srv.URL + "/gone/teams"},
+	} {
+		if err := ch.notifier().Notify(notice); err == nil {
+			t.Errorf("%s: a refused delivery is not reported", ch.Type)
+		}
+	}
+	smtpSettings.Host = ""
+	if err := email.notifier().Notify(notice); err == nil {
+		t.Errorf("email: sent without SMTP_HOST")
+	}
+	if (NotificationChannel{Type: "sms"}).notifier() != nil {
+		t.Errorf("sms: got a notifier")
+	}
+}
+
+func TestDeliverNotice(t *testing.T) {
+	var mu sync.Mutex
+	posts := map[string][]map[string]interface{}{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		var body map[string]interface{}
+		if err := json.Unmarshal(b, &body); err != nil {
+			t.Errorf("%s: %v", r.URL.Path, err)
+		}
+		mu.Lock()
+		posts[r.URL.Path] = append(posts[r.URL.Path], body)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	mailer := newSMTPStub(t)
+	defer mailer.Close()
+	host, port, _ := net.SplitHostPort(mailer.Addr().String())
+	defer func(s SMTPSettings) { smtpSettings = s }(smtpSettings)
+	smtpSettings = SMTPSettings{Host: host, Port: port, From: "terraform@example.com"}
+
+	channels := []NotificationChannel{
+		{ChannelID: "s1", Type: "slack", URL: srv.URL + "/slack", SubscriptionRules: SubscriptionRules{OnlyFailures: true}},
+		{ChannelID: "t1", Type: "teams", URL: srv.URL + "/teams", SubscriptionRules: SubscriptionRules{Actions: []string{"apply"}}},
+		{ChannelID: "e1", Type: "email", Recipients: []string{"ops@example.com", "dev@example.com"}, SubscriptionRules: SubscriptionRules{NotifyStarted: true}},
+	}
+	notice := func(action, id, status string) ActionNotice {
+		return ActionNotice{
+			ConfigName: "infra",
+			Action:     action,
+			ActionID:   id,
+			Status:     status,
+			GitRef:     "master@abc123",
+			Summary:    "2 to add, 0 to change, 1 to destroy",
+			Duration:   90 * time.Second,
+			OutputURL:  "http://tf/v1/configuration/infra/" + action + "/" + id + ".out",
+			ErrorURL:   "http://tf/v1/configuration/infra/" + action + "/" + id + ".err",
+		}
+	}
+
+	deliverNotice(channels, notice("plan", "p1", "In-Progress"))
+	deliverNotice(channels, notice("apply", "a1", "Completed"))
+	failed := notice("destroy", "d1", "Failed")
+	failed.StderrTail = "Error: <instance> is locked"
+	deliverNotice(channels, failed)
+
+	// slack: only the failure
+	if len(posts["/slack"]) != 1 {
+		t.Fatalf("slack got %d posts, want 1", len(posts["/slack"]))
+	}
+	slack, _ := json.Marshal(posts["/slack"][0])
+	for _, want := range []string{"destroy", "d1", "infra", "Failed", "master@abc123", "is locked", failed.OutputURL, failed.ErrorURL} {
+		if !strings.Contains(string(slack), want) {
+			t.Errorf("slack message %s does not contain %q", slack, want)
+		}
+	}
+
+	// teams: only the apply
+	if len(posts["/teams"]) != 1 {
+		t.Fatalf("teams got %d posts, want 1", len(posts["/teams"]))
+	}
+	card := posts["/teams"][0]
+	if card["@type"] != "MessageCard" || card["themeColor"] != "2EB886" || card["title"] != "apply a1 for infra : Completed" {
+		t.Errorf("teams card %v", card)
+	}
+	teams, _ := json.Marshal(card)
+	for _, want := range []string{"2 to add, 0 to change, 1 to destroy", "1m30s", "http://tf/v1/configuration/infra/apply/a1.out"} {
+		if !strings.Contains(string(teams), want) {
+			t.Errorf("teams card %s does not contain %q", teams, want)
+		}
+	}
+
+	// email: every notice, the start included
+	mailer.mu.Lock()
+	defer mailer.mu.Unlock()
+	if len(mailer.mails) != 3 {
+		t.Fatalf("got %d mails, want 3", len(mailer.mails))
+	}
+	for i, subject := range []string{"plan p1 for infra : In-Progress", "apply a1 for infra : Completed", "destroy d1 for infra : Failed"} {
+		m := mailer.mails[i]
+		if m.from != "terraform@example.com" || strings.Join(m.to, ",") != "ops@example.com,dev@example.com" {
+			t.Errorf("mail %d from %s to %v", i, m.from, m.to)
+		}
+		for _, want := range []string{"Subject: " + subject + "\r\n", "To: ops@example.com, dev@example.com\r\n", "Configuration: infra\r\n", "Git ref:       master@abc123\r\n"} {
+			if !strings.Contains(m.data, want) {
+				t.Errorf("mail %d does not contain %q:\n%s", i, want, m.data)
+			}
+		}
+	}
+	if !strings.Contains(mailer.mails[2].data, "\r\nError: <instance> is locked\r\n") {
+		t.Errorf("the failure mail does not hold the error:\n%s", mailer.mails[2].data)
+	}
+}
+
+func TestResultToSlack(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m SlackMessage
+		json.NewDecoder(r.Body).Decode(&m)
+		got = append(got, r.URL.Path+" "+m.Channel)
+		if r.URL.Path == "/api" {
+			w.Write([]byte(`{"ok": true, "ts": "1.2"}`))
+		}
+	}))
+	defer srv.Close()
+	defer func(api, hook, token, channel string, channels map[string]string) {
+		slackAPIURL, DefaultIncomingWebHook, slackBotToken, slackChannel, slackChannels = api, hook, token, channel, channels
+	}(slackAPIURL, DefaultIncomingWebHook, slackBotToken, slackChannel, slackChannels)
+	slackAPIURL = srv.URL + "/api"
+
+	tests := []struct {
+		name     string
+		token    string
+		channels map[string]string
+		config   string
+		webhook  string
+		want     string
+		ts       string
+	}{
+		{"default webhook", "", nil, "infra", "", "/default ", ""},
+		{"request webhook", "", nil, "infra", srv.URL + "/request", "/request ", ""},
+		{"no default webhook for a tenant", "", nil, "acme/infra", "", "", ""},
+		{"request webhook of a tenant", "", nil, "acme/infra", srv.URL + "/request", "/request ", ""},
+		{"default channel", "xoxb", nil, "infra", "", "/api #general", "1.2"},
+		{"request webhook wins over the bot token", "xoxb", nil, "infra", srv.URL + "/request", "/request ", ""},
+		{"no default channel for a tenant", "xoxb", nil, "acme/infra", "", "", ""},
+		{"channel of the tenant", "xoxb", map[string]string{"acme": "#acme"}, "acme/infra", "", "/api #acme", "1.2"},
+		{"channel of another tenant", "xoxb", map[string]string{"acme": "#acme"}, "globex/infra", "", "", ""},
+	}
+	for _, tt := range tests {
+		got = nil
+		DefaultIncomingWebHook = srv.URL + "/default"
+		slackBotToken, slackChannel, slackChannels = tt.token, "", tt.channels
+		if tt.token != "" {
+			DefaultIncomingWebHook, slackChannel = "", "#general"
+		}
+		ts := ResultToSlack(ActionNotice{ConfigName: tt.config, Action: "plan", ActionID: "a1", Status: "In-Progress"}, tt.webhook, "")
+		if ts != tt.ts {
+			t.Errorf("%s: got ts %q, want %q", tt.name, ts, tt.ts)
+		}
+		if tt.want == "" && len(got) != 0 || tt.want != "" && (len(got) != 1 || got[0] != tt.want) {
+			t.Errorf("%s: posted %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStartActionPostsToSlackInBackground(t *testing.T) {
+	saved := store
+	defer func() { store = saved }()
+	SetStore(NewMemoryStore())
+
+	release := make(chan struct{})
+	posts := make(chan SlackMessage, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m SlackMessage
+		json.NewDecoder(r.Body).Decode(&m)
+		if m.ThreadTS == "" {
+			<-release
+		}
+		posts <- m
+		w.Write([]byte(`{"ok": true, "ts": "1.2"}`))
+	}))
+	defer srv.Close()
+	defer func(api, hook, token, channel string, channels map[string]string) {
+		slackAPIURL, DefaultIncomingWebHook, slackBotToken, slackChannel, slackChannels = api, hook, token, channel, channels
+	}(slackAPIURL, DefaultIncomingWebHook, slackBotToken, slackChannel, slackChannels)
+	slackAPIURL, DefaultIncomingWebHook, slackBotToken, slackChannel, slackChannels = srv.URL, "", "xoxb", "#general", nil
+
+	runner := func(ctx context.Context, confDir, repoName, randomID string) error { return nil }
+	finished := make(chan ActionResponse, 1)
+	started := make(chan ActionResponse, 1)
+	go func() {
+		started <- startActionWith(context.Background(), nil, "infra", "plan", "http://tf/v1/configuration/infra/plan", "", runner, nil,
+			func(result ActionResponse) { finished <- result })
+	}()
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Error("the action waited for slack to answer the start notice")
+	}
+	close(release)
+
+	first, followUp := <-posts, <-posts
+	if first.ThreadTS != "" || !strings.Contains(first.Text, "In-Progress") {
+		t.Errorf("the first post is %+v", first)
+	}
+	if followUp.ThreadTS != "1.2" || !strings.Contains(followUp.Text, "Completed") {
+		t.Errorf("the follow-up is %+v, want it threaded under 1.2", followUp)
+	}
+	<-finished
+}
@@ -0,0 +1,641 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+//The OpenAPI document is generated from the router and the request and
+//response types, so it lists the routes as they are served. apiOperations
+//adds what the router does not know, a summary and the types of the bodies,
+//it is the only description of the operations. CheckAPIOperations reports
+//the routes missing from it, they are documented without their responses.
+
+// apiParam is a query or header parameter of an operation.
+type apiParam struct {
+	name        string
+	in          string
+	typ         string
+	description string
+}
+
+// apiOperation documents the operation of a route, keyed by method and path
+// template in apiOperations. The responses map a status to a value of the
+// body type, nil without a body and a string for plain text.
+type apiOperation struct {
+	summary   string
+	tag       string
+	params    []apiParam
+	request   interface{}
+	responses map[int]interface{}
+}
+
+var slackWebhookHeader = apiParam{"SLACK_WEBHOOK_URL", "header", "string", "Slack webhook notified about the action"}
+
+var auditFilters = []apiParam{
+	{"user", "query", "string", "Caller identity"},
+	{"configuration", "query", "string", "Configuration name"},
+	{"from", "query", "string", "Start of the time range, RFC 3339"},
+	{"to", "query", "string", "End of the time range, RFC 3339"},
+}
+
+var apiOperations = map[string]apiOperation{
+	"GET /": {
+		summary:   "Redirect to the swagger ui, or to this document for a JSON request",
+		tag:       "server",
+		responses: map[int]interface{}{302: nil},
+	},
+	"GET /openapi.json": {
+		summary:   "OpenAPI document of the API",
+		tag:       "server",
+		responses: map[int]interface{}{200: map[string]interface{}{}},
+	},
+	"GET /healthz": {
+		summary:   "Liveness of the server, it does not check its dependencies",
+		tag:       "server",
+		responses: map[int]interface{}{200: HealthResponse{}},
+	},
+	"GET /readyz": {
+		summary:   "Readiness of the server, with the result of each dependency check",
+		tag:       "server",
+		responses: map[int]interface{}{200: ReadyResponse{}, 503: ReadyResponse{}},
+	},
+	"GET /version": {
+		summary:   "Version of the server, and of terraform and git",
+		tag:       "server",
+		responses: map[int]interface{}{200: VersionResponse{}},
+	},
+	"GET /metrics": {
+		summary:   "Metrics of the server in the Prometheus text format",
+		tag:       "server",
+		responses: map[int]interface{}{200: ""},
+	},
+
+	"POST /v1/configuration": {
+		summary:   "Clone the configuration repo and run terraform init, in the background as the init action of the configuration",
+		tag:       "configuration",
+		request:   ConfigRequest{},
+		responses: map[int]interface{}{202: ConfigResponse{}},
+	},
+	"DELETE /v1/configuration/{repo_name}": {
+		summary:   "Delete the configuration repo. It is refused while its state tracks resources, unless forced",
+		tag:       "configuration",
+		params:    []apiParam{{"force", "query", "boolean", "Delete even though the state tracks resources"}},
+		responses: map[int]interface{}{200: nil},
+	},
+	"GET /v1/configuration/{repo_name}/settings": {
+		summary:   "Get the settings of the configuration",
+		tag:       "configuration",
+		responses: map[int]interface{}{200: ConfigSettings{}},
+	},
+	"PUT /v1/configuration/{repo_name}/settings": {
+		summary:   "Change the settings of the configuration, like prevent_destroy",
+		tag:       "configuration",
+		request:   ConfigSettingsRequest{},
+		responses: map[int]interface{}{200: ConfigSettings{}},
+	},
+
+	"POST /v1/configuration/{repo_name}/plan": {
+		summary:   "Execute plan for the configuration",
+		tag:       "actions",
+		params:    []apiParam{slackWebhookHeader},
+		responses: map[int]interface{}{202: ActionResponse{}},
+	},
+	"POST /v1/configuration/{repo_name}/show": {
+		summary:   "Execute show for the configuration",
+		tag:       "actions",
+		params:    []apiParam{slackWebhookHeader},
+		responses: map[int]interface{}{202: ActionResponse{}},
+	},
+	"POST /v1/configuration/{repo_name}/apply": {
+		summary:   "Execute apply for the configuration, with an optional time to live of the environment",
+		tag:       "actions",
+		params:    []apiParam{slackWebhookHeader},
+		request:   TTLRequest{},
+		responses: map[int]interface{}{202: ActionResponse{}},
+	},
+	"POST /v1/configuration/{repo_name}/destroy": {
+		summary:   "Execute destroy for the configuration. It needs the configuration name in confirm, or a second request with the confirmation token returned by the first one",
+		tag:       "actions",
+		params:    []apiParam{slackWebhookHeader},
+		request:   DestroyRequest{},
+		responses: map[int]interface{}{202: ActionResponse{}, 428: DestroyConfirmation{}},
+	},
+	"GET /v1/configuration/{repo_name}/{action}": {
+		summary:   "Get the last run of the action",
+		tag:       "actions",
+		responses: map[int]interface{}{200: ActionResponse{}},
+	},
+	"GET /v1/configuration/{repo_name}/{action}/{actionID}/status": {
+		summary:   "Get the status of the action",
+		tag:       "actions",
+		responses: map[int]interface{}{200: StatusResponse{}},
+	},
+	"GET /v1/configuration/{repo_name}/{action}/{actionID}/log": {
+		summary:   "Get the output and errors of the action",
+		tag:       "actions",
+		responses: map[int]interface{}{200: ActionDetails{}},
+	},
+	"GET /v1/configuration/{repo_name}/{action}/{log_file}": {
+		summary:   "Get a log file of the action, like the ones linked in the notifications",
+		tag:       "actions",
+		responses: map[int]interface{}{200: ""},
+	},
+
+	"POST /v1/apikeys": {
+		summary:   "Issue an API key. The key is only returned in this response, the server keeps its hash",
+		tag:       "access",
+		request:   APIKeyRequest{},
+		responses: map[int]interface{}{201: APIKeyResponse{}},
+	},
+	"GET /v1/apikeys": {
+		summary:   "List the API keys of the caller",
+		tag:       "access",
+		responses: map[int]interface{}{200: []APIKey{}},
+	},
+	"DELETE /v1/apikeys/{key_id}": {
+		summary:   "Revoke an API key. It is kept, marked revoked, for the record",
+		tag:       "access",
+		responses: map[int]interface{}{200: nil},
+	},
+	"POST /v1/grants": {
+		summary:   "Grant a role on all configurations",
+		tag:       "access",
+		request:   GrantRequest{},
+		responses: map[int]interface{}{200: Grant{}},
+	},
+	"GET /v1/grants": {
+		summary:   "List the roles granted on all configurations",
+		tag:       "access",
+		responses: map[int]interface{}{200: []Grant{}},
+	},
+	"DELETE /v1/grants/{subject}": {
+		summary:   "Revoke the role of a subject on all configurations",
+		tag:       "access",
+		responses: map[int]interface{}{200: nil},
+	},
+	"POST /v1/configuration/{repo_name}/grants": {
+		summary:   "Grant a role on the configuration",
+		tag:       "access",
+		request:   GrantRequest{},
+		responses: map[int]interface{}{200: Grant{}},
+	},
+	"GET /v1/configuration/{repo_name}/grants": {
+		summary:   "List the roles granted on the configuration",
+		tag:       "access",
+		responses: map[int]interface{}{200: []Grant{}},
+	},
+	"DELETE /v1/configuration/{repo_name}/grants/{subject}": {
+		summary:   "Revoke the role of a subject on the configuration",
+		tag:       "access",
+		responses: map[int]interface{}{200: nil},
+	},
+	"GET /v1/audit": {
+		summary:   "Query the audit log, newest first",
+		tag:       "access",
+		params:    append(auditFilters, apiParam{"limit", "query", "integer", "Maximum number of entries, 1000 at most"}),
+		responses: map[int]interface{}{200: []AuditEntry{}},
+	},
+	"GET /v1/audit/export": {
+		summary:   "Export the audit log as JSON Lines, oldest first",
+		tag:       "access",
+		params:    auditFilters,
+		responses: map[int]interface{}{200: ""},
+	},
+
+	"PUT /v1/configuration/{repo_name}/drift": {
+		summary:   "Enable or disable drift detection for the configuration",
+		tag:       "automation",
+		request:   DriftRequest{},
+		responses: map[int]interface{}{200: DriftStatus{}},
+	},
+	"GET /v1/configuration/{repo_name}/drift": {
+		summary:   "Get the result of the last drift check for the configuration",
+		tag:       "automation",
+		responses: map[int]interface{}{200: DriftStatus{}},
+	},
+	"POST /v1/configuration/{repo_name}/schedules": {
+		summary:   "Register a recurring plan, apply or destroy for the configuration",
+		tag:       "automation",
+		request:   ScheduleRequest{},
+		responses: map[int]interface{}{201: Schedule{}},
+	},
+	"GET /v1/configuration/{repo_name}/schedules": {
+		summary:   "List the recurring actions of the configuration with their next and last run times",
+		tag:       "automation",
+		responses: map[int]interface{}{200: []Schedule{}},
+	},
+	"DELETE /v1/configuration/{repo_name}/schedules/{schedule_id}": {
+		summary:   "Remove a recurring action of the configuration",
+		tag:       "automation",
+		responses: map[int]interface{}{200: nil},
+	},
+	"GET /v1/configuration/{repo_name}/ttl": {
+		summary:   "Get the expiry of the configuration's environment",
+		tag:       "automation",
+		responses: map[int]interface{}{200: EnvironmentTTL{}},
+	},
+	"POST /v1/configuration/{repo_name}/ttl/extend": {
+		summary:   "Extend the expiry of the configuration's environment",
+		tag:       "automation",
+		request:   TTLExtendRequest{},
+		responses: map[int]interface{}{200: EnvironmentTTL{}},
+	},
+	"GET /v1/expiring": {
+		summary:   "List the active environments expiring within the given window",
+		tag:       "automation",
+		params:    []apiParam{{"within", "query", "string", "Duration window, defaults to 24h"}},
+		responses: map[int]interface{}{200: []EnvironmentTTL{}},
+	},
+	"PUT /v1/configuration/{repo_name}/trigger": {
+		summary:   "Map a git repository and branch to the configuration",
+		tag:       "automation",
+		request:   GitTriggerRequest{},
+		responses: map[int]interface{}{200: GitTrigger{}},
+	},
+	"DELETE /v1/configuration/{repo_name}/trigger": {
+		summary:   "Remove the git trigger of the configuration",
+		tag:       "automation",
+		responses: map[int]interface{}{200: nil},
+	},
+	"POST /v1/webhooks/{provider}": {
+		summary: "Receive a git push or pull request event and plan the matching configurations",
+		tag:     "automation",
+		params: []apiParam{
+			{"X-Hub-Signature-256", "header", "string", "Signature of a GitHub event"},
+			{"X-GitHub-Event", "header", "string", "Type of a GitHub event"},
+			{"X-Gitlab-Token", "header", "string", "Secret token of a GitLab event"},
+			{"X-Gitlab-Event", "header", "string", "Type of a GitLab event"},
+		},
+		request:   map[string]interface{}{},
+		responses: map[int]interface{}{202: []ActionResponse{}},
+	},
+
+	"POST /v1/configuration/{repo_name}/webhooks": {
+		summary:   "Register an HTTP endpoint notified about the configuration's actions",
+		tag:       "notifications",
+		request:   WebhookRequest{},
+		responses: map[int]interface{}{201: WebhookTarget{}},
+	},
+	"GET /v1/configuration/{repo_name}/webhooks": {
+		summary:   "List the webhook targets of the configuration",
+		tag:       "notifications",
+		responses: map[int]interface{}{200: []WebhookTarget{}},
+	},
+	"DELETE /v1/configuration/{repo_name}/webhooks/{webhook_id}": {
+		summary:   "Remove a webhook target of the configuration",
+		tag:       "notifications",
+		responses: map[int]interface{}{200: nil},
+	},
+	"GET /v1/configuration/{repo_name}/webhooks/{webhook_id}/deliveries": {
+		summary:   "List the deliveries made to a webhook target, newest first",
+		tag:       "notifications",
+		params:    []apiParam{{"status", "query", "string", "Only deliveries with this status"}},
+		responses: map[int]interface{}{200: []WebhookDelivery{}},
+	},
+	"POST /v1/configuration/{repo_name}/channels": {
+		summary:   "Subscribe a slack, teams or email channel to the configuration's notifications",
+		tag:       "notifications",
+		request:   ChannelRequest{},
+		responses: map[int]interface{}{201: NotificationChannel{}},
+	},
+	"GET /v1/configuration/{repo_name}/channels": {
+		summary:   "List the notification channels of the configuration",
+		tag:       "notifications",
+		responses: map[int]interface{}{200: []NotificationChannel{}},
+	},
+	"PUT /v1/configuration/{repo_name}/channels/{channel_id}": {
+		summary:   "Replace a notification channel of the configuration",
+		tag:       "notifications",
+		request:   ChannelRequest{},
+		responses: map[int]interface{}{200: NotificationChannel{}},
+	},
+	"DELETE /v1/configuration/{repo_name}/channels/{channel_id}": {
+		summary:   "Remove a notification channel of the configuration",
+		tag:       "notifications",
+		responses: map[int]interface{}{200: nil},
+	},
+}
+
+// pathParamDescriptions describes the path parameters by name.
+var pathParamDescriptions = map[string]string{
+	"repo_name":   "Name of the configuration",
+	"action":      "Name of the action: init, plan, apply, destroy, show or drift",
+	"actionID":    "ID of the action",
+	"log_file":    "Name of the log file of the action",
+	"key_id":      "ID of the API key",
+	"subject":     "Subject the role is granted to",
+	"schedule_id": "ID of the schedule",
+	"webhook_id":  "ID of the webhook target",
+	"channel_id":  "ID of the notification channel",
+	"provider":    "github or gitlab",
+}
+
+var pathParam = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// openAPIBuilder collects the schemas of the types met while documenting the
+// operations, as components referenced by their Go type name, and the tags of
+// the operations.
+type openAPIBuilder struct {
+	schemas map[string]interface{}
+	tags    map[string]bool
+}
+
+// schema returns the schema of the Go type. Named structs are added to the
+// components and referenced.
+func (b *openAPIBuilder) schema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		if _, ok := b.schemas[t.Name()]; !ok {
+			// Set before the fields, a type referencing itself finds it.
+			b.schemas[t.Name()] = nil
+			b.schemas[t.Name()] = b.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+	}
+	return map[string]interface{}{}
+}
+
+// structSchema describes the fields as encoding/json writes them, with the
+// fields of embedded structs inlined. A field is required when its json tag
+// says so.
+func (b *openAPIBuilder) structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := strings.Split(f.Tag.Get("json"), ",")
+			if tag[0] == "-" || f.PkgPath != "" && !f.Anonymous {
+				continue
+			}
+			if f.Anonymous && tag[0] == "" && f.Type.Kind() == reflect.Struct {
+				addFields(f.Type)
+				continue
+			}
+			name := tag[0]
+			if name == "" {
+				name = f.Name
+			}
+			s := b.schema(f.Type)
+			if description := f.Tag.Get("description"); description != "" {
+				if _, ok := s["$ref"]; ok {
+					s = map[string]interface{}{"allOf": []interface{}{s}}
+				}
+				s["description"] = description
+			}
+			properties[name] = s
+			for _, option := range tag[1:] {
+				if option == "required" {
+					required = append(required, name)
+				}
+			}
+		}
+	}
+	addFields(t)
+
+	s := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// content is the content of a body of the value's type.
+func (b *openAPIBuilder) content(v interface{}) map[string]interface{} {
+	if _, ok := v.(string); ok {
+		return map[string]interface{}{"text/plain": map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}}
+	}
+	return map[string]interface{}{"application/json": map[string]interface{}{"schema": b.schema(reflect.TypeOf(v))}}
+}
+
+func (b *openAPIBuilder) operation(method, tpl string) map[string]interface{} {
+	op, documented := apiOperations[method+" "+tpl]
+
+	var params []interface{}
+	for _, m := range pathParam.FindAllStringSubmatch(tpl, -1) {
+		param := map[string]interface{}{"name": m[1], "in": "path", "required": true, "schema": map[string]interface{}{"type": "string"}}
+		if description := pathParamDescriptions[m[1]]; description != "" {
+			param["description"] = description
+		}
+		params = append(params, param)
+	}
+	for _, p := range op.params {
+		params = append(params, map[string]interface{}{
+			"name": p.name, "in": p.in, "description": p.description,
+			"schema": map[string]interface{}{"type": p.typ},
+		})
+	}
+
+	responses := map[string]interface{}{
+		"default": map[string]interface{}{"description": "Error", "content": b.content("")},
+	}
+	if !documented {
+		responses["default"] = map[string]interface{}{"description": "Undocumented"}
+	}
+	for status, body := range op.responses {
+		response := map[string]interface{}{"description": http.StatusText(status)}
+		if body != nil {
+			response["content"] = b.content(body)
+		}
+		responses[strconv.Itoa(status)] = response
+	}
+
+	o := map[string]interface{}{
+		"operationId": operationID(method, tpl),
+		"responses":   responses,
+	}
+	if op.summary != "" {
+		o["summary"] = op.summary
+	}
+	if op.tag != "" {
+		o["tags"] = []string{op.tag}
+		b.tags[op.tag] = true
+	}
+	if len(params) > 0 {
+		o["parameters"] = params
+	}
+	if op.request != nil {
+		o["requestBody"] = map[string]interface{}{"content": b.content(op.request)}
+	}
+	if isPublicPath(pathParam.ReplaceAllString(tpl, "x")) {
+		o["security"] = []interface{}{}
+	}
+	return o
+}
+
+// operationID names the operation after its method and path, like
+// post_configuration_repo_name_plan.
+func operationID(method, tpl string) string {
+	id := strings.ToLower(method)
+	for _, part := range strings.Split(pathParam.ReplaceAllString(tpl, "$1"), "/") {
+		if part != "" && part != "v1" {
+			id += "_" + strings.NewReplacer(".", "_", "-", "_").Replace(part)
+		}
+	}
+	return id
+}
+
+// walkOperations calls fn with the method and path template of each
+// operation of the router.
+func walkOperations(r *mux.Router, fn func(method, tpl string)) error {
+	return r.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		tpl, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		// The static files are served under a path prefix.
+		if re, err := route.GetPathRegexp(); err != nil || !strings.HasSuffix(re, "$") {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			methods = []string{"GET"}
+		}
+		for _, method := range methods {
+			fn(method, tpl)
+		}
+		return nil
+	})
+}
+
+// CheckAPIOperations fails when a route of the router is missing from
+// apiOperations, or when an operation there matches no route.
+func CheckAPIOperations(r *mux.Router) error {
+	unused := map[string]bool{}
+	for key := range apiOperations {
+		unused[key] = true
+	}
+	var missing []string
+	err := walkOperations(r, func(method, tpl string) {
+		key := method + " " + tpl
+		if _, ok := apiOperations[key]; !ok {
+			missing = append(missing, key)
+		}
+		delete(unused, key)
+	})
+	if err != nil {
+		return err
+	}
+	var stale []string
+	for key := range unused {
+		stale = append(stale, key)
+	}
+	sort.Strings(missing)
+	sort.Strings(stale)
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, "routes without an operation: "+strings.Join(missing, ", "))
+	}
+	if len(stale) > 0 {
+		problems = append(problems, "operations without a route: "+strings.Join(stale, ", "))
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// openAPIDocument documents the routes of the router.
+func openAPIDocument(r *mux.Router) (map[string]interface{}, error) {
+	b := &openAPIBuilder{schemas: map[string]interface{}{}, tags: map[string]bool{}}
+	paths := map[string]map[string]interface{}{}
+	err := walkOperations(r, func(method, tpl string) {
+		if paths[tpl] == nil {
+			paths[tpl] = map[string]interface{}{}
+		}
+		paths[tpl][strings.ToLower(method)] = b.operation(method, tpl)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for tag := range b.tags {
+		names = append(names, tag)
+	}
+	sort.Strings(names)
+	tags := make([]interface{}, 0, len(names))
+	for _, tag := range names {
+		tags = append(tags, map[string]interface{}{"name": tag})
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "IBM Cloud Provider API",
+			"description": "Run terraform plan, apply, destroy and show on configurations cloned from git.",
+			"version":     Version,
+			"contact":     map[string]interface{}{"email": "sakshiag@in.ibm.com"},
+		},
+		"tags":  tags,
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": b.schemas,
+			"securitySchemes": map[string]interface{}{
+				"apiKey": map[string]interface{}{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+				"bearer": map[string]interface{}{"type": "http", "scheme": "bearer", "description": "API key or JWT"},
+			},
+		},
+		"security": []interface{}{
+			map[string]interface{}{"apiKey": []string{}},
+			map[string]interface{}{"bearer": []string{}},
+		},
+	}, nil
+}
+
+// OpenAPIHandler serves the OpenAPI 3 document of the routes of the router.
+// It is generated on the first request, once the routes are registered.
+func OpenAPIHandler(r *mux.Router) func(w http.ResponseWriter, req *http.Request) {
+	var once sync.Once
+	var output []byte
+	var err error
+	return func(w http.ResponseWriter, req *http.Request) {
+		once.Do(func() {
+			var doc map[string]interface{}
+			doc, err = openAPIDocument(r)
+			if err == nil {
+				output, err = json.MarshalIndent(doc, "", "  ")
+			}
+		})
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		w.Header().Set("content-type", "application/json")
+		w.Write(output)
+	}
+}
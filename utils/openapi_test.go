@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestCheckAPIOperations(t *testing.T) {
+	defer func(ops map[string]apiOperation) { apiOperations = ops }(apiOperations)
+	apiOperations = map[string]apiOperation{
+		"GET /healthz":                         {summary: "Liveness", responses: map[int]interface{}{200: HealthResponse{}}},
+		"DELETE /v1/configuration/{repo_name}": {summary: "Delete", responses: map[int]interface{}{200: nil}},
+	}
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+
+	r := mux.NewRouter()
+	r.PathPrefix("/swagger-ui").Handler(http.NotFoundHandler())
+	r.HandleFunc("/healthz", handler).Methods("GET")
+	r.HandleFunc("/v1/configuration/{repo_name}", handler).Methods("DELETE")
+	if err := CheckAPIOperations(r); err != nil {
+		t.Errorf("every route is documented, got %v", err)
+	}
+
+	r.HandleFunc("/v1/configuration/{repo_name}/plan", handler).Methods("POST")
+	r.HandleFunc("/v1/configuration/{repo_name}/{action}/{log_file}", handler)
+	delete(apiOperations, "GET /healthz")
+	apiOperations["GET /version"] = apiOperation{summary: "Version"}
+	err := CheckAPIOperations(r)
+	if err == nil {
+		t.Fatal("the undocumented routes are not reported")
+	}
+	for _, want := range []string{"GET /healthz", "POST /v1/configuration/{repo_name}/plan", "GET /v1/configuration/{repo_name}/{action}/{log_file}", "operations without a route: GET /version"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("%q does not contain %s", err, want)
+		}
+	}
+
+	// An undocumented route is listed without inventing a response.
+	doc, err := openAPIDocument(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := doc["paths"].(map[string]map[string]interface{})["/v1/configuration/{repo_name}/plan"]["post"].(map[string]interface{})
+	responses := op["responses"].(map[string]interface{})
+	if _, ok := responses["200"]; ok || len(responses) != 1 {
+		t.Errorf("got responses %v, want only the default one", responses)
+	}
+}
@@ -0,0 +1,224 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Roles, each includes the permissions of the ones before it.
+const (
+	RoleViewer   = "viewer"
+	RolePlanner  = "planner"
+	RoleOperator = "operator"
+	RoleAdmin    = "admin"
+)
+
+var roleLevels = map[string]int{RoleViewer: 1, RolePlanner: 2, RoleOperator: 3, RoleAdmin: 4}
+
+// allConfigurations is the configuration name of a grant covering every
+// configuration of a tenant.
+const allConfigurations = "*"
+
+// tenantAll is the configuration name of the grants covering the tenant.
+func tenantAll(tenant string) string {
+	return qualifiedName(tenant, allConfigurations)
+}
+
+// grantScope returns the configuration named by {repo_name}, or all the
+// configurations of the caller's tenant without it.
+func grantScope(r *http.Request) string {
+	if _, ok := mux.Vars(r)["repo_name"]; ok {
+		return configOf(r)
+	}
+	return tenantAll(tenantOf(r))
+}
+
+// actionRoles is the role needed to run each action.
+var actionRoles = map[string]string{
+	"plan":    RolePlanner,
+	"show":    RolePlanner,
+	"apply":   RoleOperator,
+	"destroy": RoleAdmin,
+}
+
+// GrantRequest -
+type GrantRequest struct {
+	Subject string `json:"subject,required" description:"User or service the role is granted to"`
+	Role    string `json:"role,required" description:"viewer, planner, operator or admin"`
+}
+
+// Grant -
+type Grant struct {
+	ConfigName string    `json:"id" description:"Name of the configuration, * for all of them"`
+	Subject    string    `json:"subject"`
+	Role       string    `json:"role"`
+	GrantedBy  string    `json:"granted_by"`
+	Created    time.Time `json:"created"`
+}
+
+// roleOf returns the highest role the identity holds on the configuration,
+// either granted on it or on all configurations.
+func roleOf(id Identity, repoName string) (string, error) {
+	if authDisabled || id.Method == "bootstrap" {
+		return RoleAdmin, nil
+	}
+	if id.Subject == "" {
+		return "", nil
+	}
+
+	grants, err := store.FindSubjectGrants(id.Subject)
+	if err != nil {
+		return "", err
+	}
+	all := tenantAll(tenantOfConfig(repoName))
+	role := ""
+	for _, g := range grants {
+		if (g.ConfigName == repoName || g.ConfigName == all) && roleLevels[g.Role] > roleLevels[role] {
+			role = g.Role
+		}
+	}
+	return role, nil
+}
+
+// authorize checks that the caller holds at least the role on the
+// configuration. It writes a 403 (or 500) and returns false otherwise.
+func authorize(w http.ResponseWriter, r *http.Request, repoName, required string) bool {
+	id, _ := IdentityFrom(r)
+	role, err := roleOf(id, repoName)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return false
+	}
+	if roleLevels[role] >= roleLevels[required] {
+		return true
+	}
+
+	reason := fmt.Sprintf("Forbidden: %s needs the %s role on configuration %s", id.Subject, required, repoName)
+	if role != "" {
+		reason += ", it has " + role
+	}
+	http.Error(w, reason, 403)
+	return false
+}
+
+// RequireRole wraps a configuration handler so that it only runs for callers
+// holding at least the role on the configuration named by {repo_name}.
+// Without {repo_name} the role must be granted on all configurations.
+func RequireRole(role string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if authorize(w, r, grantScope(r), role) {
+			h(w, r)
+		}
+	}
+}
+
+// visibleConfigs returns the configurations the caller may view, or all=true
+// when it may view every configuration of its tenant.
+func visibleConfigs(r *http.Request) (all bool, names []string, err error) {
+	id, _ := IdentityFrom(r)
+	role, err := roleOf(id, tenantAll(tenantOf(r)))
+	if err != nil || role != "" {
+		return role != "", nil, err
+	}
+
+	// The subject may hold grants in other tenants, they are not visible here.
+	grants, err := store.FindSubjectGrants(id.Subject)
+	for _, g := range grants {
+		if tenantOfConfig(g.ConfigName) == tenantOf(r) {
+			names = append(names, g.ConfigName)
+		}
+	}
+	return false, names, err
+}
+
+// grantRole records the role for the subject on the configuration, replacing
+// the role it held before.
+func grantRole(repoName, subject, role, grantedBy string) error {
+	return store.SaveGrant(Grant{
+		ConfigName: repoName,
+		Subject:    subject,
+		Role:       role,
+		GrantedBy:  grantedBy,
+		Created:    time.Now(),
+	})
+}
+
+// GrantCreateHandler handles request to grant a role on the configuration.
+func GrantCreateHandler(w http.ResponseWriter, r *http.Request) {
+	repoName := grantScope(r)
+	caller, _ := IdentityFrom(r)
+
+	b, err := ioutil.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	var msg GrantRequest
+	err = json.Unmarshal(b, &msg)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	if msg.Subject == "" {
+		http.Error(w, "EMPTY SUBJECT", 400)
+		return
+	}
+	if _, ok := roleLevels[msg.Role]; !ok {
+		http.Error(w, fmt.Sprintf("Invalid role %q, it must be viewer, planner, operator or admin", msg.Role), 400)
+		return
+	}
+
+	err = grantRole(repoName, msg.Subject, msg.Role, caller.Subject)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	output, err := json.MarshalIndent(Grant{ConfigName: repoName, Subject: msg.Subject, Role: msg.Role, GrantedBy: caller.Subject, Created: time.Now()}, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.Write(output)
+}
+
+// GrantListHandler handles request to list the grants on the configuration.
+func GrantListHandler(w http.ResponseWriter, r *http.Request) {
+	grants, err := store.FindGrants(grantScope(r))
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	if grants == nil {
+		grants = []Grant{}
+	}
+
+	output, err := json.MarshalIndent(grants, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.Write(output)
+}
+
+// GrantDeleteHandler handles request to revoke a subject's role on the configuration.
+func GrantDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	err := store.DeleteGrant(grantScope(r), mux.Vars(r)["subject"])
+	if err == ErrNotFound {
+		http.Error(w, "There is no grant for this request.", 404)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+}
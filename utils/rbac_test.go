@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestVisibleConfigs(t *testing.T) {
+	saved := store
+	defer func() { store = saved }()
+	SetStore(NewMemoryStore())
+	grantRole("web", "alice", RoleViewer, "admin")
+	grantRole("acme/web", "alice", RoleOperator, "admin")
+	grantRole("acme/db", "alice", RoleViewer, "admin")
+	grantRole("globex/*", "alice", RoleAdmin, "admin")
+	grantRole("globex/*", "bob", RoleViewer, "admin")
+
+	tests := []struct {
+		name, subject, tenant string
+		all                   bool
+		want                  []string
+	}{
+		{"default tenant", "alice", defaultTenant, false, []string{"web"}},
+		{"grants of the tenant only", "alice", "acme", false, []string{"acme/db", "acme/web"}},
+		{"all the configurations of the tenant", "alice", "globex", true, nil},
+		{"no grant in the tenant", "bob", "acme", false, nil},
+	}
+	for _, tt := range tests {
+		r := withIdentity(httptest.NewRequest("GET", "/v1/expiring", nil), Identity{Subject: tt.subject, Method: "api_key", Tenant: tt.tenant})
+		all, names, err := visibleConfigs(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Strings(names)
+		if all != tt.all || !reflect.DeepEqual(names, tt.want) {
+			t.Errorf("%s: got %v, %v, want %v, %v", tt.name, all, names, tt.all, tt.want)
+		}
+	}
+}
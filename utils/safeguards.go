@@ -0,0 +1,230 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"time"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// destroyConfirmationTTL is how long a destroy confirmation token stays valid.
+var destroyConfirmationTTL = 5 * time.Minute
+
+// ConfigSettings -
+type ConfigSettings struct {
+	ConfigName     string `json:"id" description:"Name of the configuration"`
+	PreventDestroy bool   `json:"prevent_destroy" description:"Refuse to destroy or delete the configuration"`
+}
+
+// ConfigSettingsRequest -
+type ConfigSettingsRequest struct {
+	PreventDestroy *bool `json:"prevent_destroy,omitempty" description:"Refuse to destroy or delete the configuration"`
+}
+
+// DestroyRequest -
+type DestroyRequest struct {
+	Confirm           string `json:"confirm,omitempty" description:"The name of the configuration, to confirm the destroy"`
+	ConfirmationToken string `json:"confirmation_token,omitempty" description:"The token returned by a destroy request without confirmation"`
+}
+
+// DestroyConfirmation -
+type DestroyConfirmation struct {
+	ConfigName        string    `json:"id" description:"Name of the configuration"`
+	ConfirmationToken string    `json:"confirmation_token" description:"Send it back to destroy the configuration"`
+	Subject           string    `json:"-"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+func configSettings(repoName string) (ConfigSettings, error) {
+	conf, err := store.FindConfiguration(repoName)
+	if err == ErrNotFound {
+		err = nil
+	}
+	return ConfigSettings{ConfigName: repoName, PreventDestroy: conf.PreventDestroy}, err
+}
+
+// setPreventDestroy records the flag, also for configurations cloned before
+// they were kept in the store.
+func setPreventDestroy(repoName string, prevent bool) error {
+	conf, err := store.FindConfiguration(repoName)
+	if err == ErrNotFound {
+		conf, err = Configuration{ConfigName: repoName, Created: time.Now()}, nil
+	}
+	if err != nil {
+		return err
+	}
+	conf.PreventDestroy = prevent
+	return store.SaveConfiguration(conf)
+}
+
+// destroyPrevented returns an error when the configuration must not be
+// destroyed, or its settings can not be read.
+func destroyPrevented(repoName string) error {
+	settings, err := configSettings(repoName)
+	if err != nil {
+		return err
+	}
+	if settings.PreventDestroy {
+		return fmt.Errorf("Configuration %s has prevent_destroy set, clear it in its settings first", displayName(repoName))
+	}
+	return nil
+}
+
+// stateResources counts the resources tracked in the state of the configuration.
+// It reads both the modules of the 0.11 state format and the resources of later ones.
+func stateResources(repoName string) (int, error) {
+	b, err := ioutil.ReadFile(path.Join(stateDir, repoName+".tfstate"))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+
+	var state struct {
+		Modules []struct {
+			Resources map[string]interface{} `json:"resources"`
+		} `json:"modules"`
+		Resources []interface{} `json:"resources"`
+	}
+	err = json.Unmarshal(b, &state)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the state of %s: %v", displayName(repoName), err)
+	}
+	n := len(state.Resources)
+	for _, m := range state.Modules {
+		n += len(m.Resources)
+	}
+	return n, nil
+}
+
+// confirmDestroy checks the confirmation of a destroy request. Without one it
+// issues a confirmation token, responds 428 with it and returns false.
+func confirmDestroy(s *mgo.Session, w http.ResponseWriter, r *http.Request, repoName string, msg DestroyRequest) bool {
+	if msg.Confirm != "" {
+		if msg.Confirm != displayName(repoName) {
+			http.Error(w, fmt.Sprintf("The confirmation %q does not match the configuration name %s", msg.Confirm, displayName(repoName)), 400)
+			return false
+		}
+		return true
+	}
+	if s == nil {
+		http.Error(w, "Confirm the destroy with the configuration name in confirm", 428)
+		return false
+	}
+
+	session := s.Copy()
+	defer session.Close()
+
+	caller, _ := IdentityFrom(r)
+	c := session.DB(dbName).C("destroyConfirmations")
+
+	if msg.ConfirmationToken != "" {
+		// A token is used once, by the caller it was issued to.
+		err := c.Remove(bson.M{
+			"configname":        repoName,
+			"confirmationtoken": msg.ConfirmationToken,
+			"subject":           caller.Subject,
+			"expiresat":         bson.M{"$gt": time.Now()},
+		})
+		if err == mgo.ErrNotFound {
+			http.Error(w, "The confirmation token is invalid or has expired", 400)
+			return false
+		}
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return false
+		}
+		return true
+	}
+
+	confirmation := DestroyConfirmation{
+		ConfigName:        repoName,
+		ConfirmationToken: newActionID(),
+		Subject:           caller.Subject,
+		ExpiresAt:         time.Now().Add(destroyConfirmationTTL),
+	}
+	err := c.Insert(confirmation)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return false
+	}
+	confirmation.ConfigName = displayName(repoName)
+
+	output, err := json.MarshalIndent(confirmation, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return false
+	}
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(428)
+	w.Write(output)
+	return false
+}
+
+// ConfigSettingsHandler handles request to get the settings of the configuration.
+func ConfigSettingsHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		repoName := configOf(r)
+
+		settings, err := configSettings(repoName)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		settings.ConfigName = displayName(repoName)
+
+		output, err := json.MarshalIndent(settings, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		w.Header().Set("content-type", "application/json")
+		w.Write(output)
+	}
+}
+
+// ConfigSettingsUpdateHandler handles request to change the settings of the configuration.
+func ConfigSettingsUpdateHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
+	get := ConfigSettingsHandler(s)
+	return func(w http.ResponseWriter, r *http.Request) {
+		repoName := configOf(r)
+
+		if _, err := os.Stat(configDir(repoName)); err != nil {
+			http.Error(w, "There is no config repo for this request.", 404)
+			return
+		}
+
+		b, err := ioutil.ReadAll(r.Body)
+		defer r.Body.Close()
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+
+		var msg ConfigSettingsRequest
+		err = json.Unmarshal(b, &msg)
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		if msg.PreventDestroy != nil {
+			err = setPreventDestroy(repoName, *msg.PreventDestroy)
+			if err != nil {
+				http.Error(w, err.Error(), 500)
+				return
+			}
+		}
+
+		get(w, r)
+	}
+}
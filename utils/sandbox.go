@@ -0,0 +1,386 @@
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+//Terraform runs in a sandbox: each action works on its own copy of the
+//configuration and of its state, below sandboxWorkDir, with a minimal
+//environment and, when configured, as an unprivileged user of its own in a
+//cgroup with CPU and memory limits. The wall-clock limit is the action timeout.
+//The workspaces of a configuration are used one at a time, since the state
+//lock of terraform only covers the copy it is given.
+
+// sandboxWorkDir holds the working copies of the running actions, set by Configure.
+var sandboxWorkDir string
+
+// defaultSandboxEnv are the variables passed from the server environment to
+// terraform, the IBM Cloud provider credentials and proxy settings.
+const defaultSandboxEnv = "IC_API_KEY,IBMCLOUD_API_KEY,BM_API_KEY,IAAS_CLASSIC_USERNAME,IAAS_CLASSIC_API_KEY,SL_USERNAME,SL_API_KEY,IC_REGION,IBMCLOUD_REGION,HTTP_PROXY,HTTPS_PROXY,NO_PROXY,TF_LOG"
+
+// The settings of the sandbox are set by Configure, see SandboxConfig.
+var sandboxEnvPassthrough = strings.Split(defaultSandboxEnv, ",")
+
+// sandboxCPUSeconds limits the CPU time of a terraform process, 0 for no limit.
+var sandboxCPUSeconds int
+
+// sandboxMemoryMB limits the memory of a terraform run, 0 for no limit.
+var sandboxMemoryMB int
+
+// sandboxUID and sandboxGID, when set, are the unprivileged user terraform
+// runs as. The server must run as root to switch to it.
+var sandboxUID, sandboxGID int
+
+// sandboxUserPool holds the free users, as offsets from sandboxUID and
+// sandboxGID, when the runs get one each. See setSandboxUsers.
+var sandboxUserPool chan int
+
+// sandboxLeases are the offsets of the users leased by the running actions.
+var sandboxLeases = struct {
+	sync.Mutex
+	m map[string]int
+}{m: make(map[string]int)}
+
+// sandboxCgroup is a cgroup v2 directory delegated to the server. Each run
+// gets a child cgroup with the memory and CPU limits.
+var sandboxCgroup string
+
+// sandboxCPUs limits the CPUs a run may use in its cgroup, 0 for no limit.
+var sandboxCPUs float64
+
+// sandboxEnv is the whole environment of terraform in the workspace.
+func sandboxEnv(home string) []string {
+	env := []string{
+		"PATH=" + envOrDefault("PATH", "/usr/local/bin:/usr/bin:/bin"),
+		"HOME=" + home,
+		"TF_IN_AUTOMATION=1",
+		"TF_INPUT=0",
+	}
+	for _, name := range sandboxEnvPassthrough {
+		name = strings.TrimSpace(name)
+		if v, ok := os.LookupEnv(name); ok && name != "" {
+			env = append(env, name+"="+v)
+		}
+	}
+	return env
+}
+
+// setSandboxUsers lets terraform run as the n users from uid on, with the
+// groups from gid on, each run as one of its own, so that a run can not read
+// the workspaces of the others. With n of 0 or 1 all the runs share uid.
+func setSandboxUsers(uid, gid, n int) {
+	sandboxUID, sandboxGID = uid, gid
+	sandboxUserPool = nil
+	if uid > 0 && n > 1 {
+		sandboxUserPool = make(chan int, n)
+		for i := 0; i < n; i++ {
+			sandboxUserPool <- i
+		}
+	}
+}
+
+// leaseSandboxUser waits for a free user for the action and returns the func
+// giving it back.
+func leaseSandboxUser(randomID string) func() {
+	if sandboxUID <= 0 {
+		return func() {}
+	}
+	pool := sandboxUserPool
+	offset := 0
+	if pool != nil {
+		offset = <-pool
+	}
+	sandboxLeases.Lock()
+	sandboxLeases.m[randomID] = offset
+	sandboxLeases.Unlock()
+	return func() {
+		sandboxLeases.Lock()
+		delete(sandboxLeases.m, randomID)
+		sandboxLeases.Unlock()
+		if pool != nil {
+			pool <- offset
+		}
+	}
+}
+
+// sandboxUser returns the user and group terraform runs as for the action,
+// 0 when it runs as the server.
+func sandboxUser(randomID string) (uid, gid int) {
+	if sandboxUID <= 0 {
+		return 0, 0
+	}
+	sandboxLeases.Lock()
+	defer sandboxLeases.Unlock()
+	offset := sandboxLeases.m[randomID]
+	return sandboxUID + offset, sandboxGID + offset
+}
+
+// sandboxCommand prepares the command to run in the workspace dir, with the
+// sandbox environment and limits. cleanup must be called once it has exited.
+func sandboxCommand(ctx context.Context, name string, args []string, dir, randomID string) (cmd *exec.Cmd, cleanup func(), err error) {
+	// The CPU and, without cgroups, memory limits are rlimits set by the shell
+	// that then execs terraform.
+	var ulimits []string
+	if sandboxCPUSeconds > 0 {
+		ulimits = append(ulimits, fmt.Sprintf("ulimit -t %d", sandboxCPUSeconds))
+	}
+	if sandboxMemoryMB > 0 && !sandboxCgroupAvailable() {
+		ulimits = append(ulimits, fmt.Sprintf("ulimit -v %d", sandboxMemoryMB*1024))
+	}
+	if len(ulimits) > 0 {
+		args = append([]string{"-c", strings.Join(ulimits, " && ") + ` && exec "$0" "$@"`, name}, args...)
+		name = "/bin/sh"
+	}
+
+	cmd = exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	cmd.Env = sandboxEnv(dir)
+
+	cleanup, err = isolateProcess(cmd, randomID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cmd, cleanup, nil
+}
+
+// workspace is the working copy of a configuration for one action.
+type workspace struct {
+	repoName  string
+	Dir       string
+	StateDir  string
+	stateHash string
+}
+
+// configLocks hold a lock per configuration name, see lockConfig.
+var configLocks = struct {
+	sync.Mutex
+	m map[string]*sync.Mutex
+}{m: make(map[string]*sync.Mutex)}
+
+// lockConfig waits until no other workspace of the configuration is in use
+// and returns the func releasing it.
+func lockConfig(repoName string) func() {
+	configLocks.Lock()
+	l, ok := configLocks.m[repoName]
+	if !ok {
+		l = &sync.Mutex{}
+		configLocks.m[repoName] = l
+	}
+	configLocks.Unlock()
+
+	l.Lock()
+	return l.Unlock
+}
+
+// newWorkspace copies the configuration in srcDir, without its git metadata,
+// and the state of the configuration into a new workspace.
+func newWorkspace(srcDir, repoName, randomID string) (*workspace, error) {
+	root := filepath.Join(sandboxWorkDir, randomID)
+	ws := &workspace{
+		repoName: repoName,
+		Dir:      filepath.Join(root, "config"),
+		StateDir: filepath.Join(root, "state"),
+	}
+
+	// The sandbox user may pass through the work directory, not list it.
+	err := os.MkdirAll(sandboxWorkDir, 0711)
+	if err == nil {
+		err = os.Chmod(sandboxWorkDir, 0711)
+	}
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(filepath.Join(ws.StateDir, repoName)), 0700)
+	}
+	if err == nil {
+		err = copyTree(srcDir, ws.Dir, true)
+	}
+	if err == nil {
+		err = copyState(filepath.Join(stateDir, repoName+".tfstate"), filepath.Join(ws.StateDir, repoName+".tfstate"))
+		if os.IsNotExist(err) {
+			err = nil
+		}
+	}
+	if err == nil {
+		ws.stateHash, err = fileHash(filepath.Join(ws.StateDir, repoName+".tfstate"))
+	}
+	if err == nil {
+		uid, gid := sandboxUser(randomID)
+		err = chownSandbox(root, uid, gid)
+	}
+	if err != nil {
+		os.RemoveAll(root)
+		return nil, fmt.Errorf("failed to prepare the workspace of %s: %v", randomID, err)
+	}
+	return ws, nil
+}
+
+// Close copies the state, when the action changed it, and the paths of keep
+// relative to the workspace back to the configuration and removes the workspace.
+func (ws *workspace) Close(confDir string, keep ...string) error {
+	defer os.RemoveAll(filepath.Dir(ws.Dir))
+
+	wsState := filepath.Join(ws.StateDir, ws.repoName+".tfstate")
+	hash, err := fileHash(wsState)
+	if err != nil {
+		return err
+	}
+	if hash != ws.stateHash {
+		err = copyState(wsState, filepath.Join(stateDir, ws.repoName+".tfstate"))
+		if err != nil {
+			return err
+		}
+	}
+	for _, p := range keep {
+		src := filepath.Join(ws.Dir, p)
+		if _, err := os.Lstat(src); err != nil {
+			continue
+		}
+		dst := filepath.Join(confDir, p)
+		os.RemoveAll(dst)
+		err = copyTree(src, dst, false)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inWorkspace runs fn on a working copy of the configuration in srcDir and
+// keeps its state, and the paths of keep, when it is done.
+func inWorkspace(srcDir, repoName, randomID string, fn func(ws *workspace) error, keep ...string) error {
+	unlock := lockConfig(repoName)
+	defer unlock()
+	release := leaseSandboxUser(randomID)
+	defer release()
+
+	ws, err := newWorkspace(srcDir, repoName, randomID)
+	if err != nil {
+		return err
+	}
+	runErr := fn(ws)
+	err = ws.Close(srcDir, keep...)
+	if runErr != nil {
+		return runErr
+	}
+	return err
+}
+
+// copyTree copies the directory src to dst, skipping .git. Symlinks are
+// copied when they resolve to a path in src and refused otherwise. With
+// linkProviders, the provider binaries installed by init are hard-linked
+// rather than copied, they are read-only for the sandbox user.
+func copyTree(src, dst string, linkProviders bool) error {
+	root, err := filepath.EvalSymlinks(src)
+	if err != nil {
+		return err
+	}
+	providers := filepath.Join(".terraform", "providers") + string(filepath.Separator)
+	return filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if info.Name() == ".git" && rel != "." {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		case info.Mode()&os.ModeSymlink != 0:
+			real, err := filepath.EvalSymlinks(p)
+			if err != nil || !insideDir(root, real) {
+				return fmt.Errorf("the symlink %s does not point to a file of the configuration", rel)
+			}
+			link, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular() && linkProviders && strings.HasPrefix(rel, providers):
+			if os.Link(p, target) == nil {
+				return nil
+			}
+			return copyFile(p, target)
+		case info.Mode().IsRegular():
+			return copyFile(p, target)
+		}
+		return nil
+	})
+}
+
+// insideDir tells whether the path p is dir or below it.
+func insideDir(dir, p string) bool {
+	rel, err := filepath.Rel(dir, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// fileHash returns the sha256 of the file, or "" when it does not exist.
+func fileHash(p string) (string, error) {
+	f, err := os.Open(p)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	return hex.EncodeToString(h.Sum(nil)), err
+}
+
+// copyState replaces the state file dst with src, readable by its owner only.
+// The copy is renamed into place, so that dst is never left half written.
+func copyState(src, dst string) error {
+	tmp := dst + ".tmp"
+	err := copyFile(src, tmp)
+	if err == nil {
+		err = os.Chmod(tmp, 0600)
+	}
+	if err == nil {
+		err = os.Rename(tmp, dst)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
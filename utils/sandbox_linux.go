@@ -0,0 +1,78 @@
+//go:build linux
+// +build linux
+
+package utils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+func sandboxCgroupAvailable() bool {
+	return sandboxCgroup != ""
+}
+
+// isolateProcess runs the command in its own process group, killed as a whole
+// on timeout, as the sandbox user and in a cgroup of its own when configured.
+func isolateProcess(cmd *exec.Cmd, randomID string) (func(), error) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		// Terraform providers are child processes, kill the whole group.
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	if uid, gid := sandboxUser(randomID); uid > 0 {
+		cmd.SysProcAttr.Credential = &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)}
+	}
+	if sandboxCgroup == "" {
+		return func() {}, nil
+	}
+
+	dir := filepath.Join(sandboxCgroup, randomID)
+	err := os.Mkdir(dir, 0755)
+	if err != nil && !os.IsExist(err) {
+		return nil, fmt.Errorf("failed to create the cgroup of %s: %v", randomID, err)
+	}
+	if sandboxMemoryMB > 0 {
+		err = ioutil.WriteFile(filepath.Join(dir, "memory.max"), []byte(strconv.Itoa(sandboxMemoryMB*1024*1024)), 0644)
+	}
+	if err == nil && sandboxCPUs > 0 {
+		err = ioutil.WriteFile(filepath.Join(dir, "cpu.max"), []byte(fmt.Sprintf("%d 100000", int(sandboxCPUs*100000))), 0644)
+	}
+	var fd *os.File
+	if err == nil {
+		fd, err = os.Open(dir)
+	}
+	if err != nil {
+		os.Remove(dir)
+		return nil, fmt.Errorf("failed to set the limits of %s: %v", randomID, err)
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = int(fd.Fd())
+
+	return func() {
+		fd.Close()
+		os.Remove(dir)
+	}, nil
+}
+
+// chownSandbox gives the workspace to the user of its run. The files
+// hard-linked from the configuration stay the server's.
+func chownSandbox(root string, uid, gid int) error {
+	if uid <= 0 {
+		return nil
+	}
+	return filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if st, ok := info.Sys().(*syscall.Stat_t); ok && info.Mode().IsRegular() && st.Nlink > 1 {
+			return nil
+		}
+		return os.Lchown(p, uid, gid)
+	})
+}
@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCopyTreeSymlinks(t *testing.T) {
+	tests := []struct {
+		name, link string
+		ok         bool
+	}{
+		{"inside", "main.tf", true},
+		{"subdir", "modules", true},
+		{"nested", "modules/vars.tf", true},
+		{"absolute", "/etc/passwd", false},
+		{"parent", "../other/terraform.tfstate", false},
+		{"dangling", "missing.tf", false},
+		// here resolves to the repo, here/.. is its parent.
+		{"through", "here/../other", false},
+	}
+	for _, tt := range tests {
+		dir, err := ioutil.TempDir("", "copytree")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		src := filepath.Join(dir, "repo")
+		os.MkdirAll(filepath.Join(src, "modules"), 0700)
+		os.MkdirAll(filepath.Join(dir, "other"), 0700)
+		ioutil.WriteFile(filepath.Join(src, "main.tf"), nil, 0600)
+		ioutil.WriteFile(filepath.Join(src, "modules", "vars.tf"), nil, 0600)
+		os.Symlink(".", filepath.Join(src, "here"))
+		os.Symlink(tt.link, filepath.Join(src, tt.name))
+
+		err = copyTree(src, filepath.Join(dir, "copy"), false)
+		if (err == nil) != tt.ok {
+			t.Errorf("copyTree with %s -> %s: %v, want ok %v", tt.name, tt.link, err, tt.ok)
+		}
+	}
+}
+
+func TestCopyTreeLinksProviders(t *testing.T) {
+	dir, err := ioutil.TempDir("", "copytree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "repo")
+	provider := filepath.Join(".terraform", "providers", "registry.terraform.io", "ibm-cloud", "ibm", "terraform-provider-ibm")
+	os.MkdirAll(filepath.Dir(filepath.Join(src, provider)), 0700)
+	ioutil.WriteFile(filepath.Join(src, provider), []byte("binary"), 0755)
+	ioutil.WriteFile(filepath.Join(src, "main.tf"), nil, 0600)
+
+	dst := filepath.Join(dir, "copy")
+	if err := copyTree(src, dst, true); err != nil {
+		t.Fatal(err)
+	}
+	for p, linked := range map[string]bool{provider: true, "main.tf": false} {
+		a, _ := os.Stat(filepath.Join(src, p))
+		b, err := os.Stat(filepath.Join(dst, p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if os.SameFile(a, b) != linked {
+			t.Errorf("%s: linked %v, want %v", p, !linked, linked)
+		}
+	}
+}
+
+func TestCopyState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "ws.tfstate")
+	dst := filepath.Join(dir, "repo.tfstate")
+	ioutil.WriteFile(src, []byte(`{"version": 4}`), 0644)
+	ioutil.WriteFile(dst, []byte(`{"version": 3}`), 0644)
+
+	if err := copyState(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := ioutil.ReadFile(dst)
+	info, _ := os.Stat(dst)
+	if string(b) != `{"version": 4}` || info.Mode().Perm() != 0600 {
+		t.Errorf("got %s with mode %v, want the new state with mode 0600", b, info.Mode().Perm())
+	}
+	if _, err := os.Stat(dst + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("the temporary copy is left: %v", err)
+	}
+}
+
+func TestCreateFileMode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tfvars")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	p := filepath.Join(dir, "terraform.tfvars")
+
+	createFile(ConfigRequest{VariableStore: &VariablesRequest{{Name: "api_key", Value: "secret"}}}, p)
+	b, _ := ioutil.ReadFile(p)
+	info, err := os.Stat(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "api_key = \"secret\" \n" || info.Mode().Perm() != 0600 {
+		t.Errorf("got %q with mode %v, want the variables with mode 0600", b, info.Mode().Perm())
+	}
+}
+
+func TestSandboxUsers(t *testing.T) {
+	defer setSandboxUsers(sandboxUID, sandboxGID, 0)
+
+	setSandboxUsers(0, 0, 0)
+	release := leaseSandboxUser("a")
+	if uid, gid := sandboxUser("a"); uid != 0 || gid != 0 {
+		t.Errorf("without a sandbox user the run is %d:%d, want the server's", uid, gid)
+	}
+	release()
+
+	setSandboxUsers(10000, 20000, 0)
+	releaseA, releaseB := leaseSandboxUser("a"), leaseSandboxUser("b")
+	if uid, gid := sandboxUser("b"); uid != 10000 || gid != 20000 {
+		t.Errorf("the shared user is %d:%d, want 10000:20000", uid, gid)
+	}
+	releaseA()
+	releaseB()
+
+	setSandboxUsers(10000, 20000, 2)
+	releaseA, releaseB = leaseSandboxUser("a"), leaseSandboxUser("b")
+	uidA, gidA := sandboxUser("a")
+	uidB, gidB := sandboxUser("b")
+	if uidA == uidB || gidA == gidB || uidA < 10000 || uidA > 10001 || uidB < 10000 || uidB > 10001 || gidA-uidA != 10000 {
+		t.Errorf("the runs are %d:%d and %d:%d, want users of their own from 10000:20000", uidA, gidA, uidB, gidB)
+	}
+
+	// A third run waits for a free user.
+	leased := make(chan func())
+	go func() { leased <- leaseSandboxUser("c") }()
+	select {
+	case <-leased:
+		t.Fatal("a third run got a user while both are in use")
+	case <-time.After(50 * time.Millisecond):
+	}
+	releaseA()
+	releaseC := <-leased
+	if uid, _ := sandboxUser("c"); uid != uidA {
+		t.Errorf("the third run is %d, want the freed %d", uid, uidA)
+	}
+	releaseB()
+	releaseC()
+}
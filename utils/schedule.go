@@ -0,0 +1,269 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gorilla/mux"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// schedulePollInterval is how often the scheduler looks for schedules that are due.
+var schedulePollInterval = 30 * time.Second
+
+// scheduledActions are the actions a schedule may run.
+var scheduledActions = map[string]bool{"plan": true, "apply": true, "destroy": true}
+
+// ScheduleRequest -
+type ScheduleRequest struct {
+	Action   string `json:"action,required" description:"Action to run: plan, apply or destroy"`
+	Cron     string `json:"cron,required" description:"Cron expression (minute hour day-of-month month day-of-week), e.g. 0 19 * * 1-5"`
+	TimeZone string `json:"time_zone,omitempty" description:"IANA time zone the cron expression is evaluated in. Defaults to UTC"`
+	Webhook  string `json:"slack_webhook_url,omitempty" description:"Slack webhook notified about the scheduled runs"`
+}
+
+// Schedule -
+type Schedule struct {
+	ScheduleID   string    `json:"schedule_id"`
+	ConfigName   string    `json:"id" description:"Name of the configuration"`
+	Action       string    `json:"action"`
+	Cron         string    `json:"cron"`
+	TimeZone     string    `json:"time_zone"`
+	Webhook      string    `json:"-"`
+	LogURL       string    `json:"-"`
+	NextRun      time.Time `json:"next_run"`
+	LastRun      time.Time `json:"last_run,omitempty"`
+	LastActionID string    `json:"last_action_id,omitempty"`
+	CreatedBy    Identity  `json:"created_by" description:"Caller the scheduled actions run as"`
+	Timestamp    string    `json:"timestamp"`
+}
+
+// ScheduleCreateHandler handles request to register a recurring action for the configuration.
+func ScheduleCreateHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session := s.Copy()
+		defer session.Close()
+
+		repoName := configOf(r)
+
+		if _, err := os.Stat(configDir(repoName)); err != nil {
+			http.Error(w, "There is no config repo for this request.", 404)
+			return
+		}
+
+		b, err := ioutil.ReadAll(r.Body)
+		defer r.Body.Close()
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+
+		var msg ScheduleRequest
+		err = json.Unmarshal(b, &msg)
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+
+		if !scheduledActions[msg.Action] {
+			http.Error(w, fmt.Sprintf("Invalid action %q, it must be one of plan, apply or destroy", msg.Action), 400)
+			return
+		}
+		if !authorize(w, r, repoName, actionRoles[msg.Action]) {
+			return
+		}
+		cron, err := parseCron(msg.Cron, msg.TimeZone)
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		next := cron.Next(time.Now())
+		if next.IsZero() {
+			http.Error(w, fmt.Sprintf("The cron expression %q never fires", msg.Cron), 400)
+			return
+		}
+
+		caller, _ := IdentityFrom(r)
+		schedule := Schedule{
+			ScheduleID: newActionID(),
+			ConfigName: repoName,
+			Action:     msg.Action,
+			Cron:       msg.Cron,
+			TimeZone:   msg.TimeZone,
+			Webhook:    msg.Webhook,
+			LogURL:     "http://" + r.Host + "/v1/configuration/" + displayName(repoName) + "/" + msg.Action,
+			NextRun:    next,
+			CreatedBy:  caller,
+			Timestamp:  time.Now().Format("20060102150405"),
+		}
+
+		c := session.DB(dbName).C("schedules")
+		err = c.Insert(schedule)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+
+		output, err := json.MarshalIndent(schedule, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(201)
+		w.Write(output)
+	}
+}
+
+// ScheduleListHandler handles request to list the schedules of the configuration.
+func ScheduleListHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session := s.Copy()
+		defer session.Close()
+
+		repoName := configOf(r)
+
+		schedules := []Schedule{}
+		c := session.DB(dbName).C("schedules")
+		err := c.Find(bson.M{"configname": repoName}).Sort("nextrun").All(&schedules)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+
+		output, err := json.MarshalIndent(schedules, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		w.Header().Set("content-type", "application/json")
+		w.Write(output)
+	}
+}
+
+// ScheduleDeleteHandler handles request to remove a schedule.
+func ScheduleDeleteHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session := s.Copy()
+		defer session.Close()
+
+		vars := mux.Vars(r)
+		repoName := configOf(r)
+		scheduleID := vars["schedule_id"]
+
+		c := session.DB(dbName).C("schedules")
+		err := c.Remove(bson.M{"configname": repoName, "scheduleid": scheduleID})
+		if err == mgo.ErrNotFound {
+			http.Error(w, "There is no schedule for this request.", 404)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+	}
+}
+
+// StartActionScheduler runs the registered schedules when they are due.
+func StartActionScheduler(s *mgo.Session) {
+	go func() {
+		ticker := time.NewTicker(schedulePollInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			runDueSchedules(s)
+		}
+	}()
+}
+
+func runDueSchedules(s *mgo.Session) {
+	session := s.Copy()
+	defer session.Close()
+
+	var due []Schedule
+	c := session.DB(dbName).C("schedules")
+	err := c.Find(bson.M{"nextrun": bson.M{"$lte": time.Now()}}).All(&due)
+	if err != nil {
+		Log.Error("Failed to load the schedules", "error", err)
+		return
+	}
+
+	for _, sch := range due {
+		ctx := withLogFields(context.Background(), "config", sch.ConfigName, "schedule_id", sch.ScheduleID)
+		now := time.Now()
+		cron, err := parseCron(sch.Cron, sch.TimeZone)
+		if err != nil || cron.Next(now).IsZero() {
+			Log.InfoContext(ctx, "Removing the schedule, it has no further runs")
+			c.Remove(bson.M{"scheduleid": sch.ScheduleID})
+			continue
+		}
+		next := cron.Next(now)
+
+		// Claim the run by moving nextrun forward, so that another server
+		// sharing the db does not start the same run.
+		err = c.Update(bson.M{"scheduleid": sch.ScheduleID, "nextrun": sch.NextRun}, bson.M{"$set": bson.M{"nextrun": next}})
+		if err == mgo.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			Log.ErrorContext(ctx, "Failed to update the schedule", "error", err)
+			continue
+		}
+		if _, err := os.Stat(configDir(sch.ConfigName)); err != nil {
+			Log.WarnContext(ctx, "Skipping the schedule, there is no config repo")
+			continue
+		}
+
+		if err := scheduleAllowed(sch); err != nil {
+			Log.WarnContext(ctx, "Skipping the schedule", "created_by", sch.CreatedBy.Subject, "error", err)
+			continue
+		}
+		if sch.Action == "destroy" {
+			if err := destroyPrevented(sch.ConfigName); err != nil {
+				Log.WarnContext(ctx, "Skipping the schedule", "error", err)
+				continue
+			}
+		}
+
+		Log.InfoContext(ctx, "Running the scheduled action", "action", sch.Action)
+		actionResponse := startAction(ctx, s, sch.ConfigName, sch.Action, sch.LogURL, sch.Webhook, nil)
+
+		err = c.Update(bson.M{"scheduleid": sch.ScheduleID}, bson.M{"$set": bson.M{"lastrun": now, "lastactionid": actionResponse.ActionID}})
+		if err != nil {
+			Log.ErrorContext(ctx, "Failed to update the schedule", "error", err)
+		}
+	}
+}
+
+// scheduleAllowed checks, for every run, that the caller who created the
+// schedule may still run its action: that it holds the role the action needs
+// and, for an API key, that the key is not revoked.
+func scheduleAllowed(sch Schedule) error {
+	id := sch.CreatedBy
+	if id.Subject == "" && !authDisabled {
+		return fmt.Errorf("the schedule has no creator, create it again")
+	}
+	if id.Method == "api_key" {
+		key, err := store.FindAPIKey(id.KeyID)
+		if err == ErrNotFound || err == nil && key.Revoked {
+			return fmt.Errorf("the API key %s of %s is revoked", id.KeyID, id.Subject)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	required := actionRoles[sch.Action]
+	role, err := roleOf(id, sch.ConfigName)
+	if err != nil {
+		return err
+	}
+	if roleLevels[role] < roleLevels[required] {
+		return fmt.Errorf("%s no longer holds the %s role on configuration %s", id.Subject, required, sch.ConfigName)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleAllowed(t *testing.T) {
+	saved := store
+	defer func() { store = saved }()
+	SetStore(NewMemoryStore())
+
+	store.SaveGrant(Grant{ConfigName: "web", Subject: "alice", Role: RoleOperator})
+	store.SaveGrant(Grant{ConfigName: "web", Subject: "bob", Role: RolePlanner})
+	store.SaveGrant(Grant{ConfigName: "acme/*", Subject: "carol", Role: RoleAdmin})
+	store.InsertAPIKey(APIKey{KeyID: "k1", Subject: "alice"})
+	store.InsertAPIKey(APIKey{KeyID: "k2", Subject: "alice"})
+	store.RevokeAPIKey("k2", time.Now())
+
+	alice := Identity{Subject: "alice", Method: "jwt"}
+	tests := []struct {
+		name    string
+		sch     Schedule
+		allowed bool
+	}{
+		{"operator applies", Schedule{ConfigName: "web", Action: "apply", CreatedBy: alice}, true},
+		{"operator may not destroy", Schedule{ConfigName: "web", Action: "destroy", CreatedBy: alice}, false},
+		{"planner plans", Schedule{ConfigName: "web", Action: "plan", CreatedBy: Identity{Subject: "bob", Method: "jwt"}}, true},
+		{"planner may no longer apply", Schedule{ConfigName: "web", Action: "apply", CreatedBy: Identity{Subject: "bob", Method: "jwt"}}, false},
+		{"no grant on the configuration", Schedule{ConfigName: "db", Action: "plan", CreatedBy: alice}, false},
+		{"grant on all the configurations of the tenant", Schedule{ConfigName: "acme/web", Action: "destroy", CreatedBy: Identity{Subject: "carol", Method: "jwt", Tenant: "acme"}}, true},
+		{"api key", Schedule{ConfigName: "web", Action: "apply", CreatedBy: Identity{Subject: "alice", Method: "api_key", KeyID: "k1"}}, true},
+		{"revoked api key", Schedule{ConfigName: "web", Action: "apply", CreatedBy: Identity{Subject: "alice", Method: "api_key", KeyID: "k2"}}, false},
+		{"deleted api key", Schedule{ConfigName: "web", Action: "apply", CreatedBy: Identity{Subject: "alice", Method: "api_key", KeyID: "k3"}}, false},
+		{"bootstrap key", Schedule{ConfigName: "web", Action: "destroy", CreatedBy: Identity{Subject: "bootstrap", Method: "bootstrap"}}, true},
+		{"no creator", Schedule{ConfigName: "web", Action: "plan"}, false},
+	}
+	for _, tt := range tests {
+		err := scheduleAllowed(tt.sch)
+		if (err == nil) != tt.allowed {
+			t.Errorf("%s: scheduleAllowed = %v, want allowed %v", tt.name, err, tt.allowed)
+		}
+	}
+
+	// Taking the grant back stops the schedule.
+	store.DeleteGrant("web", "alice")
+	if err := scheduleAllowed(Schedule{ConfigName: "web", Action: "apply", CreatedBy: alice}); err == nil {
+		t.Error("the schedule still runs after its creator's grant was deleted")
+	}
+}
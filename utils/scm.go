@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// CommitStatus is the state of a plan reported against a commit.
+type CommitStatus struct {
+	State       string // pending, success or failure
+	TargetURL   string
+	Description string
+}
+
+// SCMClient reports plan results back to the git hosting service. The repo is
+// the repository identifier used by the service's API: "owner/name" for GitHub
+// and the project path or id for GitLab. number is the pull or merge request.
+type SCMClient interface {
+	SetCommitStatus(repo, sha string, status CommitStatus) error
+	Comment(repo string, number int, body string) error
+}
+
+// scmClients holds the client used for each webhook provider, set by
+// Configure. The base URLs can be pointed at a local fake through
+// GITHUB_API_URL and GITLAB_API_URL.
+var scmClients = map[string]SCMClient{
+	"github": &githubClient{baseURL: "https://api.github.com"},
+	"gitlab": &gitlabClient{baseURL: "https://gitlab.com/api/v4"},
+}
+
+// scmContext is the name the plan status is reported under.
+const scmContext = "terraform/plan"
+
+var scmHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+func envOrDefault(name, value string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return value
+}
+
+type githubClient struct {
+	baseURL string
+	token   string
+}
+
+func (c *githubClient) SetCommitStatus(repo, sha string, status CommitStatus) error {
+	body := map[string]string{
+		"state":       status.State,
+		"target_url":  status.TargetURL,
+		"description": truncate(status.Description, 140),
+		"context":     scmContext,
+	}
+	return c.post(fmt.Sprintf("/repos/%s/statuses/%s", repo, sha), body)
+}
+
+func (c *githubClient) Comment(repo string, number int, body string) error {
+	return c.post(fmt.Sprintf("/repos/%s/issues/%d/comments", repo, number), map[string]string{"body": body})
+}
+
+func (c *githubClient) post(apiPath string, body interface{}) error {
+	header := http.Header{}
+	header.Set("Accept", "application/vnd.github+json")
+	if c.token != "" {
+		header.Set("Authorization", "token "+c.token)
+	}
+	return postSCM(c.baseURL+apiPath, header, body)
+}
+
+type gitlabClient struct {
+	baseURL string
+	token   string
+}
+
+func (c *gitlabClient) SetCommitStatus(repo, sha string, status CommitStatus) error {
+	state := status.State
+	if state == "failure" {
+		state = "failed"
+	}
+	body := map[string]string{
+		"state":       state,
+		"target_url":  status.TargetURL,
+		"description": truncate(status.Description, 255),
+		"name":        scmContext,
+	}
+	return c.post(fmt.Sprintf("/projects/%s/statuses/%s", url.PathEscape(repo), sha), body)
+}
+
+func (c *gitlabClient) Comment(repo string, number int, body string) error {
+	return c.post(fmt.Sprintf("/projects/%s/merge_requests/%s/notes", url.PathEscape(repo), strconv.Itoa(number)), map[string]string{"body": body})
+}
+
+func (c *gitlabClient) post(apiPath string, body interface{}) error {
+	header := http.Header{}
+	if c.token != "" {
+		header.Set("PRIVATE-TOKEN", c.token)
+	}
+	return postSCM(c.baseURL+apiPath, header, body)
+}
+
+func postSCM(apiURL string, header http.Header, body interface{}) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(b))
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := scmHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("POST %s returned %s", apiURL, resp.Status)
+	}
+	return nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
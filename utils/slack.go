@@ -4,62 +4,210 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
-	"os"
+	"time"
 )
 
-//DefaultIncomingWebHook for posting to slack
-var DefaultIncomingWebHook = os.Getenv("SLACK_INCOMING_WEBHOOK")
+// DefaultIncomingWebHook for posting to slack, set by Configure. It is the
+// webhook of the default tenant only.
+var DefaultIncomingWebHook string
 
-//Attachments are slack attachments
+// slackBotToken and slackChannel enable posting through the Web API, which
+// threads the follow-up messages of an action under its first post.
+// Incoming webhooks can not thread. slackChannel is the channel of the default
+// tenant, slackChannels the channels of the other tenants.
+var slackBotToken, slackChannel string
+var slackChannels map[string]string
+
+var slackAPIURL = "https://slack.com/api/chat.postMessage"
+
+var slackHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// slackStderrLines is how many of the last stderr lines a failure message shows.
+var slackStderrLines = 20
+
+// Attachments are slack attachments
 type Attachments struct {
 	Text string `json:"text,omitempty"`
 }
 
-//SlackMessage encapsulatest the message to send to slack
+// SlackText is a Block Kit text object
+type SlackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// SlackBlock is a Block Kit layout block
+type SlackBlock struct {
+	Type     string      `json:"type"`
+	Text     *SlackText  `json:"text,omitempty"`
+	Fields   []SlackText `json:"fields,omitempty"`
+	Elements []SlackText `json:"elements,omitempty"`
+}
+
+// SlackMessage encapsulatest the message to send to slack
 type SlackMessage struct {
+	Channel     string        `json:"channel,omitempty"`
+	ThreadTS    string        `json:"thread_ts,omitempty"`
 	Text        string        `json:"text,omitempty"`
+	Blocks      []SlackBlock  `json:"blocks,omitempty"`
 	Attachments []Attachments `json:"attachments,omitempty"`
 }
 
-//ComposeSlackMessage  composes the mesage to slack
-func ComposeSlackMessage(outputURL, errorURL, action, id, actionStatus string) SlackMessage {
-	//SlackMessage encapsulatest the message to send to slack
-	//id = fmt.Sprintf("%s", id)
-	//action = fmt.Sprintf("%s", action)
+// ActionNotice is what a slack message reports about an action
+type ActionNotice struct {
+	ConfigName string
+	Action     string
+	ActionID   string
+	Status     string
+	GitRef     string
+	Summary    string
+	Duration   time.Duration
+	StderrTail string
+	OutputURL  string
+	ErrorURL   string
+}
+
+// failed is whether the notice reports a failed action or drift, which both
+// need someone to look at the configuration.
+func (n ActionNotice) failed() bool {
+	return n.Status == "Failed" || n.Status == driftDetected
+}
+
+func markdown(text string) *SlackText {
+	return &SlackText{Type: "mrkdwn", Text: text}
+}
 
-	topLevelMessage := fmt.Sprintf(`Status for %s %s : %s`, action, id, actionStatus)
+// ComposeSlackMessage  composes the mesage to slack
+func ComposeSlackMessage(n ActionNotice) SlackMessage {
+	topLevelMessage := fmt.Sprintf(`Status for %s %s : %s`, n.Action, n.ActionID, n.Status)
 
-	doc := Attachments{Text: fmt.Sprintf(`<%s|See Output Logs for %s id %s>`, outputURL, action, id)}
-	doc1 := Attachments{Text: fmt.Sprintf(`<%s|See Error Logs for %s id %s>`, errorURL, action, id)}
+	fields := []SlackText{
+		*markdown("*Configuration*\n" + n.ConfigName),
+		*markdown("*Action*\n" + n.Action),
+		*markdown("*Status*\n" + n.Status),
+	}
+	if n.GitRef != "" {
+		fields = append(fields, *markdown("*Git ref*\n`" + n.GitRef + "`"))
+	}
+	if n.Summary != "" {
+		fields = append(fields, *markdown("*Changes*\n" + n.Summary))
+	}
+	if n.Duration > 0 {
+		fields = append(fields, *markdown("*Duration*\n" + n.Duration.Round(time.Second).String()))
+	}
 
-	attachements := []Attachments{doc, doc1}
+	blocks := []SlackBlock{
+		{Type: "section", Text: markdown(fmt.Sprintf("*%s* `%s` for *%s* : %s", n.Action, n.ActionID, n.ConfigName, n.Status))},
+		{Type: "section", Fields: fields},
+	}
+	if n.StderrTail != "" {
+		blocks = append(blocks, SlackBlock{Type: "section", Text: markdown("```" + truncate(n.StderrTail, 2900) + "```")})
+	}
+	blocks = append(blocks, SlackBlock{Type: "context", Elements: []SlackText{
+		*markdown(fmt.Sprintf(`<%s|See Output Logs>  |  <%s|See Error Logs>`, n.OutputURL, n.ErrorURL)),
+	}})
 
-	message := SlackMessage{Text: topLevelMessage, Attachments: attachements}
-	return message
+	return SlackMessage{Text: topLevelMessage, Blocks: blocks}
 }
 
-//PostToSlack post the message to slack
-func (m SlackMessage) PostToSlack(webhook string) {
-	slackIt, err := json.Marshal(m)
+// slackChannelOf returns the channel the bot token posts the notices of the
+// configuration to, "" when its tenant has none.
+func slackChannelOf(repoName string) string {
+	tenant := tenantOfConfig(repoName)
+	if channel, ok := slackChannels[tenant]; ok {
+		return channel
+	}
+	if tenant == defaultTenant {
+		return slackChannel
+	}
+	return ""
+}
+
+// PostToSlack post the message to slack. The webhook given with the request
+// wins, without it the message goes to its channel through the Web API and
+// the ts of the posted message is returned. Posts to a webhook return "".
+func (m SlackMessage) PostToSlack(webhook string) string {
+	if webhook == "" && slackBotToken != "" && m.Channel != "" {
+		return m.postToSlackAPI()
+	}
+	m.postToSlackWebhook(webhook)
+	return ""
+}
+
+func (m SlackMessage) postToSlackWebhook(webhook string) (err error) {
+	defer func() {
+		if err != nil {
+			slackFailures.inc("webhook")
+		}
+	}()
+	m.ThreadTS = ""
 
-	log.Printf("message %s", slackIt)
+	slackIt, err := json.Marshal(m)
 	if err != nil {
-		fmt.Printf("Error occured %v", err)
-		return
+		Log.Error("Failed to encode the slack message", "error", err)
+		return err
 	}
+	Log.Debug("Posting to slack", "message", string(slackIt))
 	if webhook == "" {
-		webhook = DefaultIncomingWebHook
+		return fmt.Errorf("no slack webhook")
 	}
 
-	resp, err := http.Post(webhook, "application/json", bytes.NewBuffer(slackIt))
+	// The webhook url is a secret, it is not logged.
+	resp, err := slackHTTPClient.Post(webhook, "application/json", bytes.NewBuffer(slackIt))
 	if err != nil {
-		fmt.Printf("Error occured while invoking callback URL %s, Error is  %v", webhook, err)
-		return
+		Log.Error("Failed to post to the slack webhook", "error", err)
+		return err
 	}
-	fmt.Printf("Posted successfully to %s\n", webhook)
-	fmt.Printf("Status code is %d\n", resp.StatusCode)
 	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		Log.Error("Failed to post to the slack webhook", "status", resp.StatusCode)
+		return fmt.Errorf("slack webhook returned %s", resp.Status)
+	}
+	Log.Debug("Posted to the slack webhook", "status", resp.StatusCode)
+	return nil
+}
 
+func (m SlackMessage) postToSlackAPI() (ts string) {
+	defer func() {
+		if ts == "" {
+			slackFailures.inc("api")
+		}
+	}()
+	slackIt, err := json.Marshal(m)
+	if err != nil {
+		Log.Error("Failed to encode the slack message", "error", err)
+		return ""
+	}
+
+	req, err := http.NewRequest("POST", slackAPIURL, bytes.NewBuffer(slackIt))
+	if err != nil {
+		Log.Error("Failed to post to slack", "error", err)
+		return ""
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+slackBotToken)
+
+	resp, err := slackHTTPClient.Do(req)
+	if err != nil {
+		Log.Error("Failed to post to the slack channel", "channel", m.Channel, "error", err)
+		return ""
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK    bool   `json:"ok"`
+		TS    string `json:"ts"`
+		Error string `json:"error"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil || !result.OK {
+		if err == nil {
+			err = fmt.Errorf("%s", result.Error)
+		}
+		Log.Error("Failed to post to the slack channel", "channel", m.Channel, "error", err)
+		return ""
+	}
+	Log.Debug("Posted to the slack channel", "channel", m.Channel, "ts", result.TS)
+	return result.TS
 }
@@ -0,0 +1,435 @@
+package utils
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	// The drivers of the sql stores.
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqlMigrations create and evolve the schema of the sql stores, in order.
+// Applied migrations are recorded in schema_migrations, a migration is
+// never changed once released: add a new one instead. The statements are
+// understood by both SQLite and PostgreSQL.
+var sqlMigrations = [][]string{
+	{
+		`CREATE TABLE actions (
+			action_id   TEXT PRIMARY KEY,
+			config_name TEXT NOT NULL,
+			action      TEXT NOT NULL,
+			timestamp   TEXT NOT NULL,
+			status      TEXT NOT NULL
+		)`,
+		`CREATE INDEX actions_config_action ON actions (config_name, action, timestamp)`,
+	},
+	{
+		`CREATE TABLE configurations (
+			config_name     TEXT PRIMARY KEY,
+			git_url         TEXT NOT NULL DEFAULT '',
+			created_by      TEXT NOT NULL DEFAULT '',
+			created         TIMESTAMP NOT NULL,
+			prevent_destroy BOOLEAN NOT NULL DEFAULT FALSE
+		)`,
+	},
+	{
+		`CREATE TABLE grants (
+			config_name TEXT NOT NULL,
+			subject     TEXT NOT NULL,
+			role        TEXT NOT NULL,
+			granted_by  TEXT NOT NULL DEFAULT '',
+			created     TIMESTAMP NOT NULL,
+			PRIMARY KEY (config_name, subject)
+		)`,
+		`CREATE INDEX grants_subject ON grants (subject)`,
+		`CREATE TABLE api_keys (
+			key_id     TEXT PRIMARY KEY,
+			name       TEXT NOT NULL,
+			subject    TEXT NOT NULL,
+			tenant     TEXT NOT NULL DEFAULT '',
+			hash       TEXT NOT NULL,
+			created_by TEXT NOT NULL DEFAULT '',
+			created    TIMESTAMP NOT NULL,
+			revoked    BOOLEAN NOT NULL DEFAULT FALSE,
+			revoked_at TIMESTAMP
+		)`,
+	},
+	{
+		`CREATE TABLE audit_log (
+			kind        TEXT NOT NULL,
+			subject     TEXT NOT NULL DEFAULT '',
+			tenant      TEXT NOT NULL DEFAULT '',
+			method      TEXT NOT NULL DEFAULT '',
+			route       TEXT NOT NULL DEFAULT '',
+			path        TEXT NOT NULL DEFAULT '',
+			config_name TEXT NOT NULL DEFAULT '',
+			action_id   TEXT NOT NULL DEFAULT '',
+			source_ip   TEXT NOT NULL DEFAULT '',
+			status      INTEGER NOT NULL DEFAULT 0,
+			result      TEXT NOT NULL DEFAULT '',
+			timestamp   TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX audit_log_tenant_timestamp ON audit_log (tenant, timestamp)`,
+	},
+}
+
+type sqlStore struct {
+	db     *sql.DB
+	driver string
+}
+
+// NewSQLStore opens the database and brings its schema up to date. driver
+// is sqlite3 or postgres, dsn the file or connection string of the database.
+func NewSQLStore(driver, dsn string) (Store, error) {
+	if driver != "sqlite3" && driver != "postgres" {
+		return nil, fmt.Errorf("Unknown sql driver %q, it must be sqlite3 or postgres", driver)
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if driver == "sqlite3" {
+		// SQLite allows a single writer, the connection is shared instead
+		// of failing with database is locked.
+		db.SetMaxOpenConns(1)
+	}
+	err = db.Ping()
+	if err == nil {
+		err = migrateSQL(db, driver)
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &sqlStore{db: db, driver: driver}, nil
+}
+
+// rebind turns the ? placeholders of the query into those of the driver.
+func rebind(driver, query string) string {
+	if driver != "postgres" {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// migrateSQL applies the migrations the database has not seen yet, each in
+// its own transaction.
+func migrateSQL(db *sql.DB, driver string) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY)`)
+	if err != nil {
+		return err
+	}
+	var current int
+	err = db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current)
+	if err != nil {
+		return err
+	}
+
+	for i := current; i < len(sqlMigrations); i++ {
+		version := i + 1
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		for _, stmt := range sqlMigrations[i] {
+			if _, err = tx.Exec(stmt); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			_, err = tx.Exec(rebind(driver, `INSERT INTO schema_migrations (version) VALUES (?)`), version)
+		}
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("schema migration %d failed: %v", version, err)
+		}
+		if err = tx.Commit(); err != nil {
+			return err
+		}
+		Log.Info("Applied the schema migration", "version", version)
+	}
+	return nil
+}
+
+func (q *sqlStore) exec(query string, args ...interface{}) (sql.Result, error) {
+	return q.db.Exec(rebind(q.driver, query), args...)
+}
+
+func (q *sqlStore) InsertAction(a ActionResponse) error {
+	_, err := q.exec(`INSERT INTO actions (action_id, config_name, action, timestamp, status) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (action_id) DO NOTHING`, a.ActionID, a.ConfigName, a.Action, a.Timestamp, a.Status)
+	return err
+}
+
+func (q *sqlStore) UpdateActionStatus(actionID, status string) error {
+	res, err := q.exec(`UPDATE actions SET status = ? WHERE action_id = ?`, status, actionID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (q *sqlStore) FindAction(configName, actionID string) (a ActionResponse, err error) {
+	err = q.db.QueryRow(rebind(q.driver, `SELECT action_id, config_name, action, timestamp, status FROM actions
+		WHERE config_name = ? AND action_id = ?`), configName, actionID).
+		Scan(&a.ActionID, &a.ConfigName, &a.Action, &a.Timestamp, &a.Status)
+	if err == sql.ErrNoRows {
+		err = ErrNotFound
+	}
+	return
+}
+
+func (q *sqlStore) FindActions(configName, action string) ([]ActionResponse, error) {
+	rows, err := q.db.Query(rebind(q.driver, `SELECT action_id, config_name, action, timestamp, status FROM actions
+		WHERE config_name = ? AND action = ? ORDER BY timestamp`), configName, action)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var actions []ActionResponse
+	for rows.Next() {
+		var a ActionResponse
+		err = rows.Scan(&a.ActionID, &a.ConfigName, &a.Action, &a.Timestamp, &a.Status)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, a)
+	}
+	return actions, rows.Err()
+}
+
+func (q *sqlStore) CreateConfiguration(c Configuration) error {
+	res, err := q.exec(`INSERT INTO configurations (config_name, git_url, created_by, created, prevent_destroy) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (config_name) DO NOTHING`,
+		c.ConfigName, c.GitURL, c.CreatedBy, c.Created.UTC(), c.PreventDestroy)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrExists
+	}
+	return nil
+}
+
+func (q *sqlStore) SaveConfiguration(c Configuration) error {
+	_, err := q.exec(`INSERT INTO configurations (config_name, git_url, created_by, created, prevent_destroy) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (config_name) DO UPDATE SET git_url = excluded.git_url, created_by = excluded.created_by,
+		created = excluded.created, prevent_destroy = excluded.prevent_destroy`,
+		c.ConfigName, c.GitURL, c.CreatedBy, c.Created.UTC(), c.PreventDestroy)
+	return err
+}
+
+func (q *sqlStore) FindConfiguration(configName string) (c Configuration, err error) {
+	err = q.db.QueryRow(rebind(q.driver, `SELECT config_name, git_url, created_by, created, prevent_destroy FROM configurations
+		WHERE config_name = ?`), configName).
+		Scan(&c.ConfigName, &c.GitURL, &c.CreatedBy, &c.Created, &c.PreventDestroy)
+	if err == sql.ErrNoRows {
+		err = ErrNotFound
+	}
+	return
+}
+
+func (q *sqlStore) DeleteConfiguration(configName string) error {
+	_, err := q.exec(`DELETE FROM configurations WHERE config_name = ?`, configName)
+	return err
+}
+
+func (q *sqlStore) SaveGrant(g Grant) error {
+	_, err := q.exec(`INSERT INTO grants (config_name, subject, role, granted_by, created) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (config_name, subject) DO UPDATE SET role = excluded.role, granted_by = excluded.granted_by,
+		created = excluded.created`,
+		g.ConfigName, g.Subject, g.Role, g.GrantedBy, g.Created.UTC())
+	return err
+}
+
+func (q *sqlStore) queryGrants(query string, args ...interface{}) ([]Grant, error) {
+	rows, err := q.db.Query(rebind(q.driver, `SELECT config_name, subject, role, granted_by, created FROM grants `+query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grants []Grant
+	for rows.Next() {
+		var g Grant
+		err = rows.Scan(&g.ConfigName, &g.Subject, &g.Role, &g.GrantedBy, &g.Created)
+		if err != nil {
+			return nil, err
+		}
+		grants = append(grants, g)
+	}
+	return grants, rows.Err()
+}
+
+func (q *sqlStore) FindGrants(configName string) ([]Grant, error) {
+	return q.queryGrants(`WHERE config_name = ? ORDER BY subject`, configName)
+}
+
+func (q *sqlStore) FindSubjectGrants(subject string) ([]Grant, error) {
+	return q.queryGrants(`WHERE subject = ?`, subject)
+}
+
+func (q *sqlStore) DeleteGrant(configName, subject string) error {
+	res, err := q.exec(`DELETE FROM grants WHERE config_name = ? AND subject = ?`, configName, subject)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (q *sqlStore) DeleteGrants(configName string) error {
+	_, err := q.exec(`DELETE FROM grants WHERE config_name = ?`, configName)
+	return err
+}
+
+func (q *sqlStore) InsertAPIKey(k APIKey) error {
+	_, err := q.exec(`INSERT INTO api_keys (key_id, name, subject, tenant, hash, created_by, created) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		k.KeyID, k.Name, k.Subject, k.Tenant, k.Hash, k.CreatedBy, k.Created.UTC())
+	return err
+}
+
+func (q *sqlStore) queryAPIKeys(query string, args ...interface{}) ([]APIKey, error) {
+	rows, err := q.db.Query(rebind(q.driver, `SELECT key_id, name, subject, tenant, hash, created_by, created, revoked, revoked_at
+		FROM api_keys `+query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []APIKey
+	for rows.Next() {
+		var k APIKey
+		var revokedAt sql.NullTime
+		err = rows.Scan(&k.KeyID, &k.Name, &k.Subject, &k.Tenant, &k.Hash, &k.CreatedBy, &k.Created, &k.Revoked, &revokedAt)
+		if err != nil {
+			return nil, err
+		}
+		k.RevokedAt = revokedAt.Time
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+func (q *sqlStore) FindAPIKey(keyID string) (APIKey, error) {
+	keys, err := q.queryAPIKeys(`WHERE key_id = ?`, keyID)
+	if err == nil && len(keys) == 0 {
+		err = ErrNotFound
+	}
+	if err != nil {
+		return APIKey{}, err
+	}
+	return keys[0], nil
+}
+
+func (q *sqlStore) FindAPIKeys(tenant, subject string) ([]APIKey, error) {
+	if tenant == "" {
+		return q.queryAPIKeys(`ORDER BY created`)
+	}
+	legacy := ""
+	if tenant == defaultTenant {
+		// Keys issued before tenants existed belong to the default tenant.
+		legacy = tenant
+	}
+	return q.queryAPIKeys(`WHERE (tenant = ? OR tenant = '' AND ? <> '') AND (subject = ? OR created_by = ?) ORDER BY created`,
+		tenant, legacy, subject, subject)
+}
+
+func (q *sqlStore) RevokeAPIKey(keyID string, at time.Time) error {
+	res, err := q.exec(`UPDATE api_keys SET revoked = TRUE, revoked_at = ? WHERE key_id = ?`, at.UTC(), keyID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (q *sqlStore) InsertAuditEntry(e AuditEntry) error {
+	_, err := q.exec(`INSERT INTO audit_log (kind, subject, tenant, method, route, path, config_name, action_id, source_ip, status, result, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.Kind, e.Subject, e.Tenant, e.Method, e.Route, e.Path, e.ConfigName, e.ActionID, e.SourceIP, e.Status, e.Result, e.Timestamp.UTC())
+	return err
+}
+
+func (q *sqlStore) FindAuditEntries(query AuditQuery, fn func(AuditEntry) error) error {
+	where := []string{"1 = 1"}
+	var args []interface{}
+	if query.Tenant != "" {
+		legacy := ""
+		if query.Tenant == defaultTenant {
+			// Entries recorded before tenants existed belong to the default tenant.
+			legacy = query.Tenant
+		}
+		where = append(where, "(tenant = ? OR tenant = '' AND ? <> '')")
+		args = append(args, query.Tenant, legacy)
+	}
+	if query.Subject != "" {
+		where = append(where, "subject = ?")
+		args = append(args, query.Subject)
+	}
+	if query.ConfigName != "" {
+		where = append(where, "config_name = ?")
+		args = append(args, query.ConfigName)
+	}
+	if !query.From.IsZero() {
+		where = append(where, "timestamp >= ?")
+		args = append(args, query.From.UTC())
+	}
+	if !query.To.IsZero() {
+		where = append(where, "timestamp < ?")
+		args = append(args, query.To.UTC())
+	}
+	order := "timestamp"
+	if query.NewestFirst {
+		order = "timestamp DESC"
+	}
+	limit := ""
+	if query.Limit > 0 {
+		limit = " LIMIT " + strconv.Itoa(query.Limit)
+	}
+
+	rows, err := q.db.Query(rebind(q.driver, `SELECT kind, subject, tenant, method, route, path, config_name, action_id, source_ip, status, result, timestamp
+		FROM audit_log WHERE `+strings.Join(where, " AND ")+` ORDER BY `+order+limit), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e AuditEntry
+		err = rows.Scan(&e.Kind, &e.Subject, &e.Tenant, &e.Method, &e.Route, &e.Path, &e.ConfigName, &e.ActionID, &e.SourceIP, &e.Status, &e.Result, &e.Timestamp)
+		if err != nil {
+			return err
+		}
+		if err = fn(e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (q *sqlStore) Ping() error {
+	return q.db.Ping()
+}
@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLiteStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sqlstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	n := 0
+	testStore(t, func() Store {
+		n++
+		s, err := NewSQLStore("sqlite3", filepath.Join(dir, fmt.Sprintf("store%d.db", n)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { s.(*sqlStore).db.Close() })
+		return s
+	})
+}
+
+func TestSQLiteMigrations(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sqlstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dsn := filepath.Join(dir, "store.db")
+
+	s, err := NewSQLStore("sqlite3", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.InsertAction(ActionResponse{ConfigName: "web", Action: "plan", ActionID: "p1", Timestamp: "20240101000000", Status: "Completed"}); err != nil {
+		t.Fatal(err)
+	}
+	s.(*sqlStore).db.Close()
+
+	// Opening the database again applies no migration twice and keeps the records.
+	s, err = NewSQLStore("sqlite3", dsn)
+	if err != nil {
+		t.Fatalf("opening a migrated database: %v", err)
+	}
+	defer s.(*sqlStore).db.Close()
+	if _, err := s.FindAction("web", "p1"); err != nil {
+		t.Errorf("the action is lost: %v", err)
+	}
+	db := s.(*sqlStore).db
+	var count, max int
+	if err := db.QueryRow(`SELECT COUNT(*), MAX(version) FROM schema_migrations`).Scan(&count, &max); err != nil {
+		t.Fatal(err)
+	}
+	if count != len(sqlMigrations) || max != len(sqlMigrations) {
+		t.Errorf("schema_migrations has %d versions up to %d, want %d", count, max, len(sqlMigrations))
+	}
+
+	// A database at an older version gets the newer migrations only.
+	if _, err := db.Exec(`DROP TABLE audit_log`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`DELETE FROM schema_migrations WHERE version = ?`, len(sqlMigrations)); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrateSQL(db, "sqlite3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.InsertAuditEntry(AuditEntry{Kind: "request"}); err != nil {
+		t.Errorf("the last migration was not applied again: %v", err)
+	}
+
+	// A failing migration is rolled back and not recorded.
+	sqlMigrations = append(sqlMigrations, []string{`CREATE TABLE half (id INTEGER)`, `NOT SQL`})
+	defer func() { sqlMigrations = sqlMigrations[:len(sqlMigrations)-1] }()
+	if err := migrateSQL(db, "sqlite3"); err == nil {
+		t.Error("a failing migration succeeded")
+	}
+	if err := db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&max); err != nil || max != len(sqlMigrations)-1 {
+		t.Errorf("schema_migrations is at %d, %v, want %d", max, err, len(sqlMigrations)-1)
+	}
+	if _, err := db.Exec(`SELECT * FROM half`); err == nil {
+		t.Error("the failed migration left its table")
+	}
+}
+
+func TestSQLStoreUnknownDriver(t *testing.T) {
+	if _, err := NewSQLStore("mysql", ""); err == nil {
+		t.Error("NewSQLStore accepted mysql")
+	}
+}
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		driver, query, want string
+	}{
+		{"sqlite3", `SELECT * FROM t WHERE a = ? AND b = ?`, `SELECT * FROM t WHERE a = ? AND b = ?`},
+		{"postgres", `SELECT * FROM t WHERE a = ? AND b = ?`, `SELECT * FROM t WHERE a = $1 AND b = $2`},
+		{"postgres", `INSERT INTO t (a, b, c, d, e, f, g, h, i, j) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			`INSERT INTO t (a, b, c, d, e, f, g, h, i, j) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`},
+		{"postgres", `SELECT 'ü' FROM t`, `SELECT 'ü' FROM t`},
+		{"postgres", `DELETE FROM t`, `DELETE FROM t`},
+	}
+	for _, tt := range tests {
+		if got := rebind(tt.driver, tt.query); got != tt.want {
+			t.Errorf("rebind(%q, %q) = %q, want %q", tt.driver, tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,576 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// ErrNotFound is returned by the store when there is no such record.
+var ErrNotFound = errors.New("not found")
+
+// ErrExists is returned by the store when the record it creates exists already.
+var ErrExists = errors.New("already exists")
+
+// Configuration -
+type Configuration struct {
+	ConfigName     string    `json:"id" description:"Name of the configuration"`
+	GitURL         string    `json:"git_url"`
+	CreatedBy      string    `json:"created_by"`
+	Created        time.Time `json:"created"`
+	PreventDestroy bool      `json:"prevent_destroy" description:"Refuse to destroy or delete the configuration"`
+}
+
+// Store keeps the configurations and their actions, the grants and API keys
+// that authorize the callers, and the audit log.
+type Store interface {
+	InsertAction(a ActionResponse) error
+	UpdateActionStatus(actionID, status string) error
+	//FindAction returns the action of the configuration, or ErrNotFound.
+	FindAction(configName, actionID string) (ActionResponse, error)
+	//FindActions returns the actions of the configuration with the action name.
+	FindActions(configName, action string) ([]ActionResponse, error)
+
+	//CreateConfiguration creates the configuration, or returns ErrExists
+	//when there is one with its name.
+	CreateConfiguration(c Configuration) error
+	//SaveConfiguration creates or replaces the configuration.
+	SaveConfiguration(c Configuration) error
+	//FindConfiguration returns the configuration, or ErrNotFound.
+	FindConfiguration(configName string) (Configuration, error)
+	DeleteConfiguration(configName string) error
+
+	//SaveGrant creates or replaces the role of the subject on the configuration.
+	SaveGrant(g Grant) error
+	//FindGrants returns the grants on the configuration, by subject.
+	FindGrants(configName string) ([]Grant, error)
+	//FindSubjectGrants returns the grants of the subject.
+	FindSubjectGrants(subject string) ([]Grant, error)
+	//DeleteGrant revokes the role of the subject on the configuration, or
+	//returns ErrNotFound.
+	DeleteGrant(configName, subject string) error
+	//DeleteGrants revokes every role on the configuration.
+	DeleteGrants(configName string) error
+
+	InsertAPIKey(k APIKey) error
+	//FindAPIKey returns the key, or ErrNotFound.
+	FindAPIKey(keyID string) (APIKey, error)
+	//FindAPIKeys returns the keys of the tenant that act as or were issued
+	//by the subject, every key when tenant is empty, oldest first.
+	FindAPIKeys(tenant, subject string) ([]APIKey, error)
+	//RevokeAPIKey marks the key revoked, or returns ErrNotFound.
+	RevokeAPIKey(keyID string, at time.Time) error
+
+	//InsertAuditEntry appends the entry to the audit log. Entries are never
+	//updated or deleted.
+	InsertAuditEntry(e AuditEntry) error
+	//FindAuditEntries calls fn with the entries matching the query, in its
+	//order, and stops at the first error fn returns.
+	FindAuditEntries(q AuditQuery, fn func(AuditEntry) error) error
+
+	//Ping tells whether the store can be reached.
+	Ping() error
+}
+
+// store is the store the server runs with, set by SetStore.
+var store Store
+
+// SetStore sets the store of configurations and actions.
+func SetStore(s Store) {
+	store = tracedStore{Store: s, system: storeSystem(s)}
+}
+
+// errMongoOnly is returned for the features kept in MongoDB only, when the
+// server runs with another store.
+func errMongoOnly(feature string) error {
+	return fmt.Errorf("%s is only available with the mongo store", feature)
+}
+
+// MongoOnly serves the handler of a feature kept in MongoDB only. With
+// another store the route answers 501 naming the feature, rather than 404.
+func MongoOnly(s *mgo.Session, feature string, h http.HandlerFunc) http.HandlerFunc {
+	if s != nil {
+		return h
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, errMongoOnly(feature).Error(), 501)
+	}
+}
+
+// dbName is the Mongo database of the server.
+var dbName = "action"
+
+// SetDBName sets the Mongo database of the server.
+func SetDBName(name string) {
+	dbName = name
+}
+
+type mongoStore struct {
+	session *mgo.Session
+}
+
+// NewMongoStore returns a store keeping its records in the Mongo database of
+// the server.
+func NewMongoStore(s *mgo.Session) Store {
+	return &mongoStore{session: s}
+}
+
+func (m *mongoStore) collection(name string, fn func(c *mgo.Collection) error) error {
+	session := m.session.Copy()
+	defer session.Close()
+	err := fn(session.DB(dbName).C(name))
+	if err == mgo.ErrNotFound {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (m *mongoStore) actions(fn func(c *mgo.Collection) error) error {
+	return m.collection("actionDetails", fn)
+}
+
+func (m *mongoStore) configurations(fn func(c *mgo.Collection) error) error {
+	return m.collection("configurations", fn)
+}
+
+func (m *mongoStore) InsertAction(a ActionResponse) error {
+	return m.actions(func(c *mgo.Collection) error {
+		err := c.Insert(a)
+		if mgo.IsDup(err) {
+			return nil
+		}
+		return err
+	})
+}
+
+func (m *mongoStore) UpdateActionStatus(actionID, status string) error {
+	return m.actions(func(c *mgo.Collection) error {
+		return c.Update(bson.M{"actionid": actionID}, bson.M{"$set": bson.M{"status": status}})
+	})
+}
+
+func (m *mongoStore) FindAction(configName, actionID string) (a ActionResponse, err error) {
+	err = m.actions(func(c *mgo.Collection) error {
+		return c.Find(bson.M{"configname": configName, "actionid": actionID}).One(&a)
+	})
+	return
+}
+
+func (m *mongoStore) FindActions(configName, action string) (actions []ActionResponse, err error) {
+	err = m.actions(func(c *mgo.Collection) error {
+		return c.Find(bson.M{"configname": configName, "action": action}).All(&actions)
+	})
+	return
+}
+
+func (m *mongoStore) CreateConfiguration(conf Configuration) error {
+	return m.configurations(func(c *mgo.Collection) error {
+		err := c.Insert(conf)
+		if mgo.IsDup(err) {
+			return ErrExists
+		}
+		return err
+	})
+}
+
+func (m *mongoStore) SaveConfiguration(conf Configuration) error {
+	return m.configurations(func(c *mgo.Collection) error {
+		_, err := c.Upsert(bson.M{"configname": conf.ConfigName}, conf)
+		return err
+	})
+}
+
+func (m *mongoStore) FindConfiguration(configName string) (conf Configuration, err error) {
+	err = m.configurations(func(c *mgo.Collection) error {
+		return c.Find(bson.M{"configname": configName}).One(&conf)
+	})
+	return
+}
+
+func (m *mongoStore) DeleteConfiguration(configName string) error {
+	err := m.configurations(func(c *mgo.Collection) error {
+		return c.Remove(bson.M{"configname": configName})
+	})
+	if err == ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+func (m *mongoStore) SaveGrant(g Grant) error {
+	return m.collection("grants", func(c *mgo.Collection) error {
+		_, err := c.Upsert(bson.M{"configname": g.ConfigName, "subject": g.Subject}, g)
+		return err
+	})
+}
+
+func (m *mongoStore) FindGrants(configName string) (grants []Grant, err error) {
+	err = m.collection("grants", func(c *mgo.Collection) error {
+		return c.Find(bson.M{"configname": configName}).Sort("subject").All(&grants)
+	})
+	return
+}
+
+func (m *mongoStore) FindSubjectGrants(subject string) (grants []Grant, err error) {
+	err = m.collection("grants", func(c *mgo.Collection) error {
+		return c.Find(bson.M{"subject": subject}).All(&grants)
+	})
+	return
+}
+
+func (m *mongoStore) DeleteGrant(configName, subject string) error {
+	return m.collection("grants", func(c *mgo.Collection) error {
+		return c.Remove(bson.M{"configname": configName, "subject": subject})
+	})
+}
+
+func (m *mongoStore) DeleteGrants(configName string) error {
+	return m.collection("grants", func(c *mgo.Collection) error {
+		_, err := c.RemoveAll(bson.M{"configname": configName})
+		return err
+	})
+}
+
+func (m *mongoStore) InsertAPIKey(k APIKey) error {
+	return m.collection("apiKeys", func(c *mgo.Collection) error {
+		return c.Insert(k)
+	})
+}
+
+func (m *mongoStore) FindAPIKey(keyID string) (k APIKey, err error) {
+	err = m.collection("apiKeys", func(c *mgo.Collection) error {
+		return c.Find(bson.M{"keyid": keyID}).One(&k)
+	})
+	return
+}
+
+func (m *mongoStore) FindAPIKeys(tenant, subject string) (keys []APIKey, err error) {
+	selector := bson.M{}
+	if tenant != "" {
+		tenants := []string{tenant}
+		if tenant == defaultTenant {
+			// Keys issued before tenants existed belong to the default tenant.
+			tenants = append(tenants, "")
+		}
+		selector = bson.M{"tenant": bson.M{"$in": tenants}, "$or": []bson.M{{"subject": subject}, {"createdby": subject}}}
+	}
+	err = m.collection("apiKeys", func(c *mgo.Collection) error {
+		return c.Find(selector).Sort("created").All(&keys)
+	})
+	return
+}
+
+func (m *mongoStore) RevokeAPIKey(keyID string, at time.Time) error {
+	return m.collection("apiKeys", func(c *mgo.Collection) error {
+		return c.Update(bson.M{"keyid": keyID}, bson.M{"$set": bson.M{"revoked": true, "revokedat": at}})
+	})
+}
+
+func (m *mongoStore) InsertAuditEntry(e AuditEntry) error {
+	return m.collection("auditLog", func(c *mgo.Collection) error {
+		return c.Insert(e)
+	})
+}
+
+func (m *mongoStore) FindAuditEntries(q AuditQuery, fn func(AuditEntry) error) error {
+	selector := bson.M{}
+	if q.Tenant == defaultTenant {
+		// Entries recorded before tenants existed belong to the default tenant.
+		selector["tenant"] = bson.M{"$in": []string{"", defaultTenant}}
+	} else if q.Tenant != "" {
+		selector["tenant"] = q.Tenant
+	}
+	if q.Subject != "" {
+		selector["subject"] = q.Subject
+	}
+	if q.ConfigName != "" {
+		selector["configname"] = q.ConfigName
+	}
+	span := bson.M{}
+	if !q.From.IsZero() {
+		span["$gte"] = q.From
+	}
+	if !q.To.IsZero() {
+		span["$lt"] = q.To
+	}
+	if len(span) > 0 {
+		selector["timestamp"] = span
+	}
+	order := "timestamp"
+	if q.NewestFirst {
+		order = "-timestamp"
+	}
+
+	return m.collection("auditLog", func(c *mgo.Collection) error {
+		iter := c.Find(selector).Sort(order).Limit(q.Limit).Iter()
+		var e AuditEntry
+		for iter.Next(&e) {
+			if err := fn(e); err != nil {
+				iter.Close()
+				return err
+			}
+			e = AuditEntry{}
+		}
+		return iter.Close()
+	})
+}
+
+func (m *mongoStore) Ping() error {
+	session := m.session.Copy()
+	defer session.Close()
+	return session.Ping()
+}
+
+// memoryStore keeps the records in memory, for running without MongoDB.
+// They are lost when the server stops.
+type memoryStore struct {
+	mu             sync.RWMutex
+	actions        []ActionResponse
+	configurations map[string]Configuration
+	grants         []Grant
+	apiKeys        []APIKey
+	auditLog       []AuditEntry
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() Store {
+	return &memoryStore{configurations: make(map[string]Configuration)}
+}
+
+func (m *memoryStore) InsertAction(a ActionResponse) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, existing := range m.actions {
+		if existing.ActionID == a.ActionID {
+			return nil
+		}
+	}
+	m.actions = append(m.actions, a)
+	return nil
+}
+
+func (m *memoryStore) UpdateActionStatus(actionID, status string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.actions {
+		if m.actions[i].ActionID == actionID {
+			m.actions[i].Status = status
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (m *memoryStore) FindAction(configName, actionID string) (ActionResponse, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, a := range m.actions {
+		if a.ConfigName == configName && a.ActionID == actionID {
+			return a, nil
+		}
+	}
+	return ActionResponse{}, ErrNotFound
+}
+
+func (m *memoryStore) FindActions(configName, action string) ([]ActionResponse, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var actions []ActionResponse
+	for _, a := range m.actions {
+		if a.ConfigName == configName && a.Action == action {
+			actions = append(actions, a)
+		}
+	}
+	sort.SliceStable(actions, func(i, j int) bool { return actions[i].Timestamp < actions[j].Timestamp })
+	return actions, nil
+}
+
+func (m *memoryStore) CreateConfiguration(c Configuration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.configurations[c.ConfigName]; ok {
+		return ErrExists
+	}
+	m.configurations[c.ConfigName] = c
+	return nil
+}
+
+func (m *memoryStore) SaveConfiguration(c Configuration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.configurations[c.ConfigName] = c
+	return nil
+}
+
+func (m *memoryStore) FindConfiguration(configName string) (Configuration, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	c, ok := m.configurations[configName]
+	if !ok {
+		return Configuration{}, ErrNotFound
+	}
+	return c, nil
+}
+
+func (m *memoryStore) DeleteConfiguration(configName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.configurations, configName)
+	return nil
+}
+
+func (m *memoryStore) Ping() error {
+	return nil
+}
+
+func (m *memoryStore) SaveGrant(g Grant) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.grants {
+		if m.grants[i].ConfigName == g.ConfigName && m.grants[i].Subject == g.Subject {
+			m.grants[i] = g
+			return nil
+		}
+	}
+	m.grants = append(m.grants, g)
+	return nil
+}
+
+func (m *memoryStore) FindGrants(configName string) ([]Grant, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var grants []Grant
+	for _, g := range m.grants {
+		if g.ConfigName == configName {
+			grants = append(grants, g)
+		}
+	}
+	sort.Slice(grants, func(i, j int) bool { return grants[i].Subject < grants[j].Subject })
+	return grants, nil
+}
+
+func (m *memoryStore) FindSubjectGrants(subject string) ([]Grant, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var grants []Grant
+	for _, g := range m.grants {
+		if g.Subject == subject {
+			grants = append(grants, g)
+		}
+	}
+	return grants, nil
+}
+
+func (m *memoryStore) DeleteGrant(configName, subject string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, g := range m.grants {
+		if g.ConfigName == configName && g.Subject == subject {
+			m.grants = append(m.grants[:i], m.grants[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (m *memoryStore) DeleteGrants(configName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	grants := m.grants[:0]
+	for _, g := range m.grants {
+		if g.ConfigName != configName {
+			grants = append(grants, g)
+		}
+	}
+	m.grants = grants
+	return nil
+}
+
+func (m *memoryStore) InsertAPIKey(k APIKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.apiKeys = append(m.apiKeys, k)
+	return nil
+}
+
+func (m *memoryStore) FindAPIKey(keyID string) (APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, k := range m.apiKeys {
+		if k.KeyID == keyID {
+			return k, nil
+		}
+	}
+	return APIKey{}, ErrNotFound
+}
+
+func (m *memoryStore) FindAPIKeys(tenant, subject string) ([]APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var keys []APIKey
+	for _, k := range m.apiKeys {
+		if tenant == "" || keyOfTenant(k, tenant) && (k.Subject == subject || k.CreatedBy == subject) {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+func (m *memoryStore) RevokeAPIKey(keyID string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.apiKeys {
+		if m.apiKeys[i].KeyID == keyID {
+			m.apiKeys[i].Revoked = true
+			m.apiKeys[i].RevokedAt = at
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// keyOfTenant tells whether the key belongs to the tenant. Keys issued before
+// tenants existed belong to the default tenant.
+func keyOfTenant(k APIKey, tenant string) bool {
+	return k.Tenant == tenant || k.Tenant == "" && tenant == defaultTenant
+}
+
+func (m *memoryStore) InsertAuditEntry(e AuditEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.auditLog = append(m.auditLog, e)
+	return nil
+}
+
+func (m *memoryStore) FindAuditEntries(q AuditQuery, fn func(AuditEntry) error) error {
+	m.mu.RLock()
+	var entries []AuditEntry
+	for _, e := range m.auditLog {
+		if q.matches(e) {
+			entries = append(entries, e)
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if q.NewestFirst {
+			return entries[i].Timestamp.After(entries[j].Timestamp)
+		}
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	if q.Limit > 0 && len(entries) > q.Limit {
+		entries = entries[:q.Limit]
+	}
+	for _, e := range entries {
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
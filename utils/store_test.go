@@ -0,0 +1,281 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore)
+}
+
+// testStore checks that the stores made by newStore keep the contract of the
+// Store interface. Each part of the contract runs on an empty store.
+func testStore(t *testing.T, newStore func() Store) {
+	t.Run("actions", func(t *testing.T) { testStoreActions(t, newStore()) })
+	t.Run("configurations", func(t *testing.T) { testStoreConfigurations(t, newStore()) })
+	t.Run("grants", func(t *testing.T) { testStoreGrants(t, newStore()) })
+	t.Run("api keys", func(t *testing.T) { testStoreAPIKeys(t, newStore()) })
+	t.Run("audit log", func(t *testing.T) { testStoreAudit(t, newStore()) })
+}
+
+func testStoreActions(t *testing.T, s Store) {
+	if err := s.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	plan2 := ActionResponse{ConfigName: "web", Action: "plan", ActionID: "p2", Timestamp: "20240102000000", Status: "In-Progress"}
+	plan1 := ActionResponse{ConfigName: "web", Action: "plan", ActionID: "p1", Timestamp: "20240101000000", Status: "In-Progress"}
+	for _, a := range []ActionResponse{
+		plan2, plan1,
+		{ConfigName: "web", Action: "apply", ActionID: "a1", Timestamp: "20240101000000", Status: "In-Progress"},
+		{ConfigName: "db", Action: "plan", ActionID: "d1", Timestamp: "20240101000000", Status: "In-Progress"},
+	} {
+		if err := s.InsertAction(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Inserting an action again leaves it as it is.
+	if err := s.InsertAction(ActionResponse{ConfigName: "web", Action: "plan", ActionID: "p1", Timestamp: "20240101000000", Status: "Failed"}); err != nil {
+		t.Errorf("InsertAction of an existing action: %v", err)
+	}
+
+	if err := s.UpdateActionStatus("p2", "Completed"); err != nil {
+		t.Fatal(err)
+	}
+	plan2.Status = "Completed"
+	if err := s.UpdateActionStatus("missing", "Completed"); err != ErrNotFound {
+		t.Errorf("UpdateActionStatus of a missing action = %v, want ErrNotFound", err)
+	}
+
+	if a, err := s.FindAction("web", "p2"); err != nil || a != plan2 {
+		t.Errorf("FindAction = %+v, %v, want %+v", a, err, plan2)
+	}
+	if _, err := s.FindAction("db", "p2"); err != ErrNotFound {
+		t.Errorf("FindAction of the action of another configuration = %v, want ErrNotFound", err)
+	}
+	actions, err := s.FindActions("web", "plan")
+	if err != nil || !reflect.DeepEqual(actions, []ActionResponse{plan1, plan2}) {
+		t.Errorf("FindActions = %+v, %v, want the plans of web oldest first", actions, err)
+	}
+	if actions, err := s.FindActions("web", "destroy"); err != nil || len(actions) != 0 {
+		t.Errorf("FindActions without actions = %+v, %v", actions, err)
+	}
+}
+
+func testStoreConfigurations(t *testing.T, s Store) {
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	web := Configuration{ConfigName: "acme/web", GitURL: "https://github.com/acme/web.git", CreatedBy: "alice", Created: created}
+	if err := s.CreateConfiguration(web); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreateConfiguration(Configuration{ConfigName: "acme/web", CreatedBy: "bob", Created: created}); err != ErrExists {
+		t.Errorf("CreateConfiguration of an existing configuration = %v, want ErrExists", err)
+	}
+	c, err := s.FindConfiguration("acme/web")
+	if err != nil || c.CreatedBy != "alice" || c.GitURL != web.GitURL || !c.Created.Equal(created) || c.PreventDestroy {
+		t.Errorf("FindConfiguration = %+v, %v, want %+v", c, err, web)
+	}
+
+	web.PreventDestroy = true
+	if err := s.SaveConfiguration(web); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := s.FindConfiguration("acme/web"); err != nil || !c.PreventDestroy {
+		t.Errorf("FindConfiguration after SaveConfiguration = %+v, %v, want prevent_destroy", c, err)
+	}
+	if err := s.SaveConfiguration(Configuration{ConfigName: "db", Created: created}); err != nil {
+		t.Errorf("SaveConfiguration of a new configuration: %v", err)
+	}
+
+	if err := s.DeleteConfiguration("acme/web"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.FindConfiguration("acme/web"); err != ErrNotFound {
+		t.Errorf("FindConfiguration of a deleted configuration = %v, want ErrNotFound", err)
+	}
+	if _, err := s.FindConfiguration("db"); err != nil {
+		t.Errorf("DeleteConfiguration deleted another configuration: %v", err)
+	}
+	if err := s.CreateConfiguration(web); err != nil {
+		t.Errorf("CreateConfiguration of a deleted configuration: %v", err)
+	}
+}
+
+func testStoreGrants(t *testing.T, s Store) {
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for _, g := range []Grant{
+		{ConfigName: "web", Subject: "carol", Role: RoleViewer, GrantedBy: "admin", Created: created},
+		{ConfigName: "web", Subject: "alice", Role: RolePlanner, GrantedBy: "admin", Created: created},
+		{ConfigName: "web", Subject: "alice", Role: RoleOperator, GrantedBy: "bob", Created: created},
+		{ConfigName: "db", Subject: "alice", Role: RoleAdmin, GrantedBy: "admin", Created: created},
+		{ConfigName: "*", Subject: "bob", Role: RoleAdmin, GrantedBy: "bootstrap", Created: created},
+	} {
+		if err := s.SaveGrant(g); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	grants, err := s.FindGrants("web")
+	if err != nil || len(grants) != 2 || grants[0].Subject != "alice" || grants[1].Subject != "carol" {
+		t.Fatalf("FindGrants = %+v, %v, want alice and carol", grants, err)
+	}
+	if g := grants[0]; g.Role != RoleOperator || g.GrantedBy != "bob" || !g.Created.Equal(created) {
+		t.Errorf("SaveGrant did not replace the grant: %+v", g)
+	}
+	grants, err = s.FindSubjectGrants("alice")
+	if err != nil || len(grants) != 2 {
+		t.Errorf("FindSubjectGrants = %+v, %v, want the grants on web and db", grants, err)
+	}
+
+	if err := s.DeleteGrant("web", "carol"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteGrant("web", "carol"); err != ErrNotFound {
+		t.Errorf("DeleteGrant of a deleted grant = %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteGrants("web"); err != nil {
+		t.Fatal(err)
+	}
+	if grants, err := s.FindGrants("web"); err != nil || len(grants) != 0 {
+		t.Errorf("FindGrants after DeleteGrants = %+v, %v", grants, err)
+	}
+	if grants, err := s.FindSubjectGrants("alice"); err != nil || len(grants) != 1 || grants[0].ConfigName != "db" {
+		t.Errorf("DeleteGrants deleted the grants on other configurations: %+v, %v", grants, err)
+	}
+	if grants, err := s.FindGrants("*"); err != nil || len(grants) != 1 {
+		t.Errorf("FindGrants on all configurations = %+v, %v", grants, err)
+	}
+}
+
+func testStoreAPIKeys(t *testing.T, s Store) {
+	at := func(day int) time.Time { return time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC) }
+	for _, k := range []APIKey{
+		{KeyID: "k1", Name: "ci", Subject: "alice", Tenant: "", Hash: "h1", CreatedBy: "bootstrap", Created: at(1)},
+		{KeyID: "k2", Name: "laptop", Subject: "alice", Tenant: defaultTenant, Hash: "h2", CreatedBy: "alice", Created: at(2)},
+		{KeyID: "k3", Name: "bot", Subject: "bot", Tenant: defaultTenant, Hash: "h3", CreatedBy: "alice", Created: at(3)},
+		{KeyID: "k4", Name: "ci", Subject: "alice", Tenant: "acme", Hash: "h4", CreatedBy: "bootstrap", Created: at(4)},
+		{KeyID: "k5", Name: "ci", Subject: "bob", Tenant: defaultTenant, Hash: "h5", CreatedBy: "bob", Created: at(5)},
+	} {
+		if err := s.InsertAPIKey(k); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	k, err := s.FindAPIKey("k2")
+	if err != nil || k.Name != "laptop" || k.Hash != "h2" || k.Tenant != defaultTenant || !k.Created.Equal(at(2)) || k.Revoked {
+		t.Errorf("FindAPIKey = %+v, %v", k, err)
+	}
+	if _, err := s.FindAPIKey("missing"); err != ErrNotFound {
+		t.Errorf("FindAPIKey of a missing key = %v, want ErrNotFound", err)
+	}
+
+	ids := func(keys []APIKey) (ids []string) {
+		for _, k := range keys {
+			ids = append(ids, k.KeyID)
+		}
+		return ids
+	}
+	for _, tt := range []struct {
+		tenant, subject string
+		want            []string
+	}{
+		{"", "", []string{"k1", "k2", "k3", "k4", "k5"}},
+		// Keys issued before tenants existed belong to the default tenant.
+		{defaultTenant, "alice", []string{"k1", "k2", "k3"}},
+		{"acme", "alice", []string{"k4"}},
+		{defaultTenant, "bob", []string{"k5"}},
+		{"other", "alice", nil},
+	} {
+		keys, err := s.FindAPIKeys(tt.tenant, tt.subject)
+		if err != nil || !reflect.DeepEqual(ids(keys), tt.want) {
+			t.Errorf("FindAPIKeys(%q, %q) = %v, %v, want %v", tt.tenant, tt.subject, ids(keys), err, tt.want)
+		}
+	}
+
+	if err := s.RevokeAPIKey("k2", at(9)); err != nil {
+		t.Fatal(err)
+	}
+	if k, err := s.FindAPIKey("k2"); err != nil || !k.Revoked || !k.RevokedAt.Equal(at(9)) {
+		t.Errorf("FindAPIKey of a revoked key = %+v, %v", k, err)
+	}
+	if err := s.RevokeAPIKey("missing", at(9)); err != ErrNotFound {
+		t.Errorf("RevokeAPIKey of a missing key = %v, want ErrNotFound", err)
+	}
+}
+
+func testStoreAudit(t *testing.T, s Store) {
+	at := func(hour int) time.Time { return time.Date(2024, 1, 1, hour, 0, 0, 0, time.UTC) }
+	entries := []AuditEntry{
+		{Kind: "request", Subject: "alice", Tenant: "", Method: "POST", Route: "/v1/configuration", Path: "/v1/configuration", ConfigName: "web", SourceIP: "10.0.0.1", Status: 201, Result: "allowed", Timestamp: at(1)},
+		{Kind: "action", Subject: "server", Tenant: defaultTenant, Route: "apply", ConfigName: "web", ActionID: "a1", Result: "Completed", Timestamp: at(3)},
+		{Kind: "request", Subject: "bob", Tenant: defaultTenant, Method: "DELETE", Route: "/v1/configuration/{repo_name}", ConfigName: "db", Status: 403, Result: "denied", Timestamp: at(2)},
+		{Kind: "request", Subject: "alice", Tenant: "acme", Method: "GET", Route: "/v1/audit", Status: 200, Result: "allowed", Timestamp: at(4)},
+	}
+	for _, e := range entries {
+		if err := s.InsertAuditEntry(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	find := func(q AuditQuery) []AuditEntry {
+		var found []AuditEntry
+		err := s.FindAuditEntries(q, func(e AuditEntry) error {
+			found = append(found, e)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("FindAuditEntries(%+v): %v", q, err)
+		}
+		return found
+	}
+	hours := func(found []AuditEntry) (hours []int) {
+		for _, e := range found {
+			hours = append(hours, e.Timestamp.UTC().Hour())
+		}
+		return hours
+	}
+
+	found := find(AuditQuery{Tenant: defaultTenant})
+	if len(found) != 3 || found[0].ConfigName != "web" {
+		t.Fatalf("FindAuditEntries of the default tenant = %+v", found)
+	}
+	if got := found[0]; !got.Timestamp.Equal(at(1)) || got.Kind != "request" || got.Subject != "alice" || got.Method != "POST" ||
+		got.Route != "/v1/configuration" || got.Path != "/v1/configuration" || got.SourceIP != "10.0.0.1" || got.Status != 201 || got.Result != "allowed" {
+		t.Errorf("the entry changed in the store: %+v, want %+v", got, entries[0])
+	}
+
+	for _, tt := range []struct {
+		name string
+		q    AuditQuery
+		want []int
+	}{
+		{"all tenants", AuditQuery{}, []int{1, 2, 3, 4}},
+		{"default tenant, oldest first", AuditQuery{Tenant: defaultTenant}, []int{1, 2, 3}},
+		{"newest first", AuditQuery{Tenant: defaultTenant, NewestFirst: true}, []int{3, 2, 1}},
+		{"limit", AuditQuery{Tenant: defaultTenant, NewestFirst: true, Limit: 2}, []int{3, 2}},
+		{"tenant", AuditQuery{Tenant: "acme"}, []int{4}},
+		{"subject", AuditQuery{Tenant: defaultTenant, Subject: "alice"}, []int{1}},
+		{"configuration", AuditQuery{Tenant: defaultTenant, ConfigName: "web"}, []int{1, 3}},
+		{"from, included", AuditQuery{From: at(2)}, []int{2, 3, 4}},
+		{"to, excluded", AuditQuery{To: at(3)}, []int{1, 2}},
+		{"from and to", AuditQuery{From: at(2), To: at(4)}, []int{2, 3}},
+		{"from and to in another zone", AuditQuery{From: at(2).In(time.FixedZone("", 5*3600))}, []int{2, 3, 4}},
+		{"none", AuditQuery{Tenant: "other"}, nil},
+	} {
+		if got := hours(find(tt.q)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: FindAuditEntries = %v, want the entries of the hours %v", tt.name, got, tt.want)
+		}
+	}
+
+	stop := errors.New("stop")
+	n := 0
+	err := s.FindAuditEntries(AuditQuery{}, func(e AuditEntry) error {
+		n++
+		return stop
+	})
+	if err != stop || n != 1 {
+		t.Errorf("FindAuditEntries went on after fn failed: %v after %d entries", err, n)
+	}
+}
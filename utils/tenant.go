@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// defaultTenant owns the callers that do not belong to a tenant. Its
+// configurations keep their plain names, as before tenants existed.
+const defaultTenant = "default"
+
+// tenantHeader lets the bootstrap key act in a tenant.
+const tenantHeader = "X-Tenant"
+
+var tenantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+func validateTenant(tenant string) error {
+	if !tenantPattern.MatchString(tenant) {
+		return fmt.Errorf("Invalid tenant %q, it must be lower case letters, digits and dashes", tenant)
+	}
+	return nil
+}
+
+// tenantOf returns the tenant of the caller.
+func tenantOf(r *http.Request) string {
+	id, _ := IdentityFrom(r)
+	if id.Tenant == "" {
+		return defaultTenant
+	}
+	return id.Tenant
+}
+
+// qualifiedName is the name a tenant's configuration is stored under, in the
+// db and below MOUNT_DIR: <tenant>/<name>, or <name> for the default tenant.
+func qualifiedName(tenant, name string) string {
+	if tenant == "" || tenant == defaultTenant {
+		return name
+	}
+	return tenant + "/" + name
+}
+
+// tenantOfConfig returns the tenant owning the configuration stored under name.
+func tenantOfConfig(name string) string {
+	if i := strings.Index(name, "/"); i > 0 {
+		return name[:i]
+	}
+	return defaultTenant
+}
+
+// displayName is the name of the configuration as its tenant addresses it in urls.
+func displayName(name string) string {
+	return path.Base(name)
+}
+
+// configOf returns the stored name of the configuration named by {repo_name},
+// qualified by the tenant of the caller, so that every lookup stays within it.
+func configOf(r *http.Request) string {
+	return qualifiedName(tenantOf(r), mux.Vars(r)["repo_name"])
+}
+
+// configDir is the directory of the configuration stored under name, in the
+// directory of its tenant, so that no configuration name meets the log, state
+// and work directories of MOUNT_DIR.
+func configDir(name string) string {
+	return path.Join(currentDir, "tenants", tenantOfConfig(name), displayName(name))
+}
+
+// moveDefaultConfigs moves the configurations cloned directly in MOUNT_DIR,
+// before the default tenant had its own directory, to MOUNT_DIR/tenants/default.
+func moveDefaultConfigs() error {
+	entries, err := ioutil.ReadDir(currentDir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() || validateConfigName(name) != nil {
+			continue
+		}
+		switch name {
+		case "tenants", "log", "state", "work":
+			continue
+		}
+		if _, err := os.Stat(path.Join(currentDir, name, ".git")); err != nil {
+			continue
+		}
+		dst := configDir(name)
+		if _, err := os.Stat(dst); err == nil {
+			Log.Warn("Not moving the configuration, the default tenant has one with its name", "config", name, "path", dst)
+			continue
+		}
+		if err := os.MkdirAll(path.Dir(dst), 0700); err != nil {
+			return err
+		}
+		if err := os.Rename(path.Join(currentDir, name), dst); err != nil {
+			return err
+		}
+		Log.Info("Moved the configuration to the default tenant", "config", name, "path", dst)
+	}
+	return nil
+}
+
+// tenantConfigs selects the configuration names owned by the tenant.
+func tenantConfigs(tenant string) interface{} {
+	if tenant == defaultTenant {
+		return bson.RegEx{Pattern: "^[^/]*$"}
+	}
+	return bson.RegEx{Pattern: "^" + regexp.QuoteMeta(tenant) + "/"}
+}
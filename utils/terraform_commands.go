@@ -3,49 +3,99 @@ package utils
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
-	"os/exec"
 	"path"
+	"regexp"
+	"strings"
 	"time"
 )
 
-//TerraformInit ...
+// TerraformInit ...
 func TerraformInit(configDir string, scenario string, timeout *time.Duration, randomID string) error {
 
 	return run("terraform", []string{"init"}, configDir, scenario, timeout, randomID)
 }
 
-//TerraformApply ...
+// TerraformApply ...
 func TerraformApply(configDir, stateDir string, scenario string, timeout *time.Duration, randomID string) error {
 	return run("terraform", []string{"apply", fmt.Sprintf("-state=%s", stateDir+"/"+scenario+".tfstate"), "-auto-approve"}, configDir, scenario, timeout, randomID)
 }
 
-//TerraformPlan ...
+// TerraformPlan ...
 func TerraformPlan(configDir string, scenario string, timeout *time.Duration, randomID string) error {
 	return run("terraform", []string{"plan"}, configDir, scenario, timeout, randomID)
 }
 
-//TerraformDestroy ...
+// TerraformDestroy ...
 func TerraformDestroy(configDir, stateDir string, scenario string, timeout *time.Duration, randomID string) error {
 
 	return run("terraform", []string{"destroy", "-force", fmt.Sprintf("-state=%s", stateDir+"/"+scenario+".tfstate")}, configDir, scenario, timeout, randomID)
 }
 
-//TerraformShow ...
+// TerraformShow ...
 func TerraformShow(configDir, stateDir string, scenario string, timeout *time.Duration, randomID string) error {
 
 	return run("terraform", []string{"show", fmt.Sprintf("%s", stateDir+"/"+scenario+".tfstate")}, configDir, scenario, timeout, randomID)
 }
 
-func run(cmdName string, args []string, configDir string, scenario string, timeout *time.Duration, randomID string) error {
-	cmd := exec.Command(cmdName, args...)
+// TerraformDriftPlan runs a refresh-only plan and returns the addresses of the
+// resources that were changed outside terraform.
+func TerraformDriftPlan(configDir, stateDir string, scenario string, timeout *time.Duration, randomID string) ([]string, error) {
+	planFile := path.Join(stateDir, scenario+".drift.tfplan")
+	defer os.Remove(planFile)
+
+	err := run("terraform", []string{"plan", "-refresh-only", "-input=false", fmt.Sprintf("-out=%s", planFile), fmt.Sprintf("-state=%s", stateDir+"/"+scenario+".tfstate")}, configDir, scenario, timeout, randomID)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd, cleanup, err := sandboxCommand(context.Background(), "terraform", []string{"show", "-json", planFile}, configDir, randomID)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var plan struct {
+		ResourceDrift []struct {
+			Address string `json:"address"`
+		} `json:"resource_drift"`
+	}
+	err = json.Unmarshal(out, &plan)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]string, 0, len(plan.ResourceDrift))
+	for _, r := range plan.ResourceDrift {
+		resources = append(resources, r.Address)
+	}
+	return resources, nil
+}
+
+// run runs the command for the action randomID, traced under its span.
+func run(cmdName string, args []string, configDir string, scenario string, timeout *time.Duration, randomID string) (err error) {
+	ctx, s := startSpan(actionContext(randomID), cmdName+" "+args[0], spanKindInternal)
+	s.set("action.id", randomID)
+	s.set("process.command_args", strings.Join(append([]string{cmdName}, args...), " "))
+	defer func() { s.finish(err) }()
+
 	if timeout != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
-		cmd = exec.CommandContext(ctx, cmdName, args...)
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
 		defer cancel()
 	}
+	cmd, cleanup, err := sandboxCommand(ctx, cmdName, args, configDir, randomID)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
 
 	stdoutFile, stderrFile, err := getLogFiles(logDir, randomID)
 	if err != nil {
@@ -54,8 +104,6 @@ func run(cmdName string, args []string, configDir string, scenario string, timeo
 	defer stdoutFile.Close()
 	defer stderrFile.Close()
 
-	cmd.Dir = configDir
-
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
 		return err
@@ -84,11 +132,13 @@ func run(cmdName string, args []string, configDir string, scenario string, timeo
 	}()
 
 	//Start the command
-	fmt.Println("Starting command", cmd.Path, cmd.Args)
+	Log.InfoContext(ctx, "Starting command", "path", cmd.Path, "args", cmd.Args)
 	err = cmd.Start()
 	if err != nil {
 		return err
 	}
+	terraformProcesses.add(1)
+	defer terraformProcesses.add(-1)
 
 	//Wait for command to finish
 	err = cmd.Wait()
@@ -98,28 +148,68 @@ func run(cmdName string, args []string, configDir string, scenario string, timeo
 	return nil
 }
 
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// changeSummary extracts the change counts ("Plan: ...", "No changes.",
+// "Apply complete! ..." or "Destroy complete! ...") from terraform output.
+func changeSummary(stdout string) string {
+	for _, line := range strings.Split(ansiEscape.ReplaceAllString(stdout, ""), "\n") {
+		line = strings.TrimSpace(line)
+		for _, prefix := range []string{"Plan:", "No changes.", "Apply complete!", "Destroy complete!"} {
+			if strings.HasPrefix(line, prefix) {
+				return line
+			}
+		}
+	}
+	return ""
+}
+
+// tailLines returns the last n lines of the terraform output.
+func tailLines(output string, n int) string {
+	lines := strings.Split(strings.TrimRight(ansiEscape.ReplaceAllString(output, ""), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
 func getLogFiles(logDir, scenario string) (stdoutFile, stderrFile *os.File, err error) {
 	stdoutPath := path.Join(logDir, scenario+".out")
 	stderrPath := path.Join(logDir, scenario+".err")
 
-	if _, err = os.Stat(stdoutPath); err == nil {
-		stdoutFile, err = os.OpenFile(stdoutPath, os.O_APPEND|os.O_WRONLY, 0600)
-	} else {
-		stdoutFile, err = os.Create(stdoutPath)
+	stdoutFile, err = os.OpenFile(stdoutPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return
 	}
+	stderrFile, err = os.OpenFile(stderrPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
 	if err != nil {
+		stdoutFile.Close()
+	}
+	return
+}
+
+// appendActionLog adds the output of a command run outside of terraform, and
+// its error, to the logs of the action.
+func appendActionLog(logID string, out []byte, cmdErr error) {
+	stdoutFile, stderrFile, err := getLogFiles(logDir, logID)
+	if err != nil {
+		Log.ErrorContext(actionContext(logID), "Failed to open the action logs", "action_id", logID, "error", err)
 		return
 	}
+	defer stdoutFile.Close()
+	defer stderrFile.Close()
 
-	if _, err := os.Stat(stderrPath); err == nil {
-		stderrFile, err = os.OpenFile(stderrPath, os.O_APPEND|os.O_WRONLY, 0600)
-	} else {
-		stderrFile, err = os.Create(stderrPath)
+	stdoutFile.Write(out)
+	if cmdErr != nil {
+		fmt.Fprintln(stderrFile, cmdErr)
 	}
-	return
 }
 
 func readLogFile(logID string) (stdout, stderr string, err error) {
+	if !idPattern.MatchString(logID) {
+		err = fmt.Errorf("Invalid action id %q", logID)
+		return
+	}
 	stdoutPath := path.Join(logDir, logID+".out")
 	stderrPath := path.Join(logDir, logID+".err")
 
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// routeTimeouts are the budgets of the routes that may take longer than the
+// default timeout, by route path template. Every other route only reads or
+// writes a few records and gets the default. Clone, init and the terraform
+// commands never run in a request, the request only starts their action.
+var routeTimeouts = map[string]time.Duration{
+	// The export streams every matching audit entry, it is not bounded.
+	"/v1/audit/export": 0,
+	// The logs are read whole and grow with a verbose TF_LOG.
+	"/v1/configuration/{repo_name}/{action}/{log_file}":     5 * time.Minute,
+	"/v1/configuration/{repo_name}/{action}/{actionID}/log": 5 * time.Minute,
+	// Every triggered plan gets a pending commit status from the git host,
+	// each call taking up to the timeout of the SCM client.
+	"/v1/webhooks/{provider}": 5 * time.Minute,
+	// Deleting removes the clone and the records of the configuration.
+	"/v1/configuration/{repo_name}": 2 * time.Minute,
+	// The readiness checks and the versions run one after the other, each
+	// bounded by checkTimeout.
+	"/readyz":  30 * time.Second,
+	"/version": 15 * time.Second,
+}
+
+// TimeoutMiddleware bounds how long the handler of a route may take, replying
+// 503 once it is over. The budget of a route is its entry in routes, then in
+// routeTimeouts, then timeout. 0 lets the route run as long as it needs, like
+// the ones streaming their response. Work that can outlast a request runs as
+// an action instead.
+func TimeoutMiddleware(timeout time.Duration, routes map[string]time.Duration) mux.MiddlewareFunc {
+	budgets := make(map[string]time.Duration, len(routeTimeouts)+len(routes))
+	for tpl, d := range routeTimeouts {
+		budgets[tpl] = d
+	}
+	for tpl, d := range routes {
+		budgets[tpl] = d
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			d := timeout
+			if route := mux.CurrentRoute(r); route != nil {
+				if tpl, err := route.GetPathTemplate(); err == nil {
+					if budget, ok := budgets[tpl]; ok {
+						d = budget
+					}
+				}
+			}
+			if d <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			http.TimeoutHandler(next, d, "Timeout!").ServeHTTP(w, r)
+		})
+	}
+}
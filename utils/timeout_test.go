@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	slow := func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		if _, ok := w.(http.Flusher); !ok && r.URL.Path == "/v1/audit/export" {
+			http.Error(w, "not streamed", 500)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		routes map[string]time.Duration
+		code   int
+	}{
+		{"default", "/v1/grants", nil, 503},
+		{"budget of the route", "/readyz", nil, 200},
+		{"unbounded route", "/v1/audit/export", nil, 200},
+		{"configured route", "/v1/grants", map[string]time.Duration{"/v1/grants": time.Second}, 200},
+		{"configured over the budget", "/readyz", map[string]time.Duration{"/readyz": 20 * time.Millisecond}, 503},
+		{"bounded export", "/v1/audit/export", map[string]time.Duration{"/v1/audit/export": 20 * time.Millisecond}, 503},
+	}
+	for _, tt := range tests {
+		r := mux.NewRouter()
+		r.Use(TimeoutMiddleware(20*time.Millisecond, tt.routes))
+		r.HandleFunc("/v1/grants", slow)
+		r.HandleFunc("/readyz", slow)
+		r.HandleFunc("/v1/audit/export", slow)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.Code != tt.code {
+			t.Errorf("%s: got %d %s, want %d", tt.name, w.Code, w.Body, tt.code)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// ClientIdentity -
+type ClientIdentity struct {
+	Subject string `yaml:"subject"`
+	Tenant  string `yaml:"tenant"`
+}
+
+// clientIdentities maps the subjects of client certificates, either the whole
+// distinguished name (CN=ci,O=Example) or CN=<common name>, to the identity
+// they authenticate as. Set by Configure.
+var clientIdentities map[string]ClientIdentity
+
+// certReloader serves the certificate and client CAs of the files as they are
+// now. The files are checked at most every interval and loaded again when
+// they changed, so that renewed certificates are picked up without a
+// restart. A reload that fails keeps the previous ones.
+type certReloader struct {
+	c        TLSConfig
+	mu       sync.Mutex
+	checked  time.Time
+	modTimes []time.Time
+	config   *tls.Config
+}
+
+// ServerTLSConfig returns the tls configuration of the server, reloading the
+// certificate files of c when they change.
+func ServerTLSConfig(c TLSConfig) (*tls.Config, error) {
+	cr := &certReloader{c: c}
+	if err := cr.load(); err != nil {
+		return nil, err
+	}
+	return &tls.Config{GetConfigForClient: cr.configForClient}, nil
+}
+
+func (cr *certReloader) files() []string {
+	files := []string{cr.c.CertFile, cr.c.KeyFile}
+	if cr.c.ClientCAFile != "" {
+		files = append(files, cr.c.ClientCAFile)
+	}
+	return files
+}
+
+func (cr *certReloader) load() error {
+	var modTimes []time.Time
+	for _, f := range cr.files() {
+		info, err := os.Stat(f)
+		if err != nil {
+			return err
+		}
+		modTimes = append(modTimes, info.ModTime())
+	}
+
+	cert, err := tls.LoadX509KeyPair(cr.c.CertFile, cr.c.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load the tls certificate: %v", err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+		NextProtos:   []string{"http/1.1"},
+	}
+	if cr.c.ClientCAFile != "" {
+		pem, err := ioutil.ReadFile(cr.c.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificate found in %s", cr.c.ClientCAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+		if cr.c.ClientAuth == "require" {
+			config.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+
+	cr.config = config
+	cr.modTimes = modTimes
+	return nil
+}
+
+// changed tells whether a file was modified since it was loaded.
+func (cr *certReloader) changed() bool {
+	for i, f := range cr.files() {
+		info, err := os.Stat(f)
+		if err != nil || !info.ModTime().Equal(cr.modTimes[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cr *certReloader) configForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	if time.Since(cr.checked) >= cr.c.ReloadInterval {
+		cr.checked = time.Now()
+		if cr.changed() {
+			if err := cr.load(); err != nil {
+				Log.Error("Keeping the previous tls certificate", "error", err)
+			} else {
+				Log.Info("Reloaded the tls certificate")
+			}
+		}
+	}
+	return cr.config, nil
+}
+
+// certIdentity returns the identity of the verified client certificate of the
+// request. ok is false when there is none.
+func certIdentity(r *http.Request) (id Identity, ok bool, err error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return Identity{}, false, nil
+	}
+	cert := r.TLS.VerifiedChains[0][0]
+	ci, found := clientIdentities[cert.Subject.String()]
+	if !found {
+		ci, found = clientIdentities["CN="+cert.Subject.CommonName]
+	}
+	if !found {
+		return Identity{}, true, fmt.Errorf("client certificate %s is not mapped to an identity", cert.Subject)
+	}
+	tenant := ci.Tenant
+	if tenant == "" {
+		tenant = defaultTenant
+	}
+	return Identity{Subject: ci.Subject, Method: "client_certificate", Tenant: tenant}, true, nil
+}
@@ -0,0 +1,187 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+//writeCert writes a self-signed certificate of the subject and its key to
+//<name>.crt and <name>.key in dir and returns the certificate.
+func writeCert(t *testing.T, dir, name string, subject pkix.Name) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               subject,
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ioutil.WriteFile(filepath.Join(dir, name+".crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	ioutil.WriteFile(filepath.Join(dir, name+".key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+//replaceFile moves src over dst and makes dst look modified later.
+func replaceFile(t *testing.T, src, dst string, modTime time.Time) {
+	if err := os.Rename(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(dst, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCertReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeCert(t, dir, "server", pkix.Name{CommonName: "old"})
+	writeCert(t, dir, "ca", pkix.Name{CommonName: "clients"})
+
+	c := TLSConfig{
+		CertFile:       filepath.Join(dir, "server.crt"),
+		KeyFile:        filepath.Join(dir, "server.key"),
+		ClientCAFile:   filepath.Join(dir, "ca.crt"),
+		ClientAuth:     "require",
+		ReloadInterval: time.Hour,
+	}
+	cr := &certReloader{c: c}
+	if err := cr.load(); err != nil {
+		t.Fatal(err)
+	}
+	servedCN := func() string {
+		config, err := cr.configForClient(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaf, err := x509.ParseCertificate(config.Certificates[0].Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return leaf.Subject.CommonName
+	}
+	config, _ := cr.configForClient(nil)
+	if config.ClientAuth != tls.RequireAndVerifyClientCert || config.ClientCAs == nil || config.MinVersion != tls.VersionTLS12 {
+		t.Errorf("client auth %v, client CAs %v, min version %x", config.ClientAuth, config.ClientCAs, config.MinVersion)
+	}
+
+	// A renewed certificate is not looked for before the interval is over.
+	later := time.Now().Add(time.Minute)
+	writeCert(t, dir, "new", pkix.Name{CommonName: "new"})
+	replaceFile(t, filepath.Join(dir, "new.crt"), c.CertFile, later)
+	replaceFile(t, filepath.Join(dir, "new.key"), c.KeyFile, later)
+	cr.checked = time.Now()
+	if cn := servedCN(); cn != "old" {
+		t.Errorf("served %s before the reload interval, want old", cn)
+	}
+	cr.checked = time.Time{}
+	if cn := servedCN(); cn != "new" {
+		t.Errorf("served %s after the files changed, want new", cn)
+	}
+
+	// A broken key keeps the certificate served.
+	ioutil.WriteFile(filepath.Join(dir, "broken.key"), []byte("not a key"), 0600)
+	replaceFile(t, filepath.Join(dir, "broken.key"), c.KeyFile, later.Add(time.Minute))
+	cr.checked = time.Time{}
+	if cn := servedCN(); cn != "new" {
+		t.Errorf("served %s after a failed reload, want new", cn)
+	}
+
+	// So does a missing CA file, and it is tried again on the next check.
+	os.Remove(c.ClientCAFile)
+	cr.checked = time.Time{}
+	if cn := servedCN(); cn != "new" {
+		t.Errorf("served %s without the CA file, want new", cn)
+	}
+
+	c.KeyFile = filepath.Join(dir, "missing.key")
+	if _, err := ServerTLSConfig(c); err == nil {
+		t.Error("ServerTLSConfig loaded without the key file")
+	}
+	// A valid pair again, with a CA file without certificates.
+	writeCert(t, dir, "server", pkix.Name{CommonName: "old"})
+	ioutil.WriteFile(filepath.Join(dir, "empty.crt"), nil, 0600)
+	c.KeyFile, c.ClientCAFile = filepath.Join(dir, "server.key"), filepath.Join(dir, "empty.crt")
+	if _, err := ServerTLSConfig(c); err == nil || !strings.Contains(err.Error(), "no certificate") {
+		t.Errorf("ServerTLSConfig with an empty CA file: %v", err)
+	}
+}
+
+func TestCertIdentity(t *testing.T) {
+	defer func(ids map[string]ClientIdentity) { clientIdentities = ids }(clientIdentities)
+	clientIdentities = map[string]ClientIdentity{
+		"CN=ci,O=Example":  {Subject: "ci-pipeline", Tenant: "acme"},
+		"CN=ci":            {Subject: "any-ci"},
+		"CN=deploy":        {Subject: "deployer", Tenant: "globex"},
+		"CN=other,O=Other": {Subject: "other"},
+	}
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name    string
+		subject *pkix.Name
+		want    Identity
+		ok      bool
+		wantErr bool
+	}{
+		{"no certificate", nil, Identity{}, false, false},
+		{"distinguished name", &pkix.Name{CommonName: "ci", Organization: []string{"Example"}}, Identity{Subject: "ci-pipeline", Method: "client_certificate", Tenant: "acme"}, true, false},
+		{"common name", &pkix.Name{CommonName: "ci", Organization: []string{"Elsewhere"}}, Identity{Subject: "any-ci", Method: "client_certificate", Tenant: defaultTenant}, true, false},
+		{"common name only", &pkix.Name{CommonName: "deploy"}, Identity{Subject: "deployer", Method: "client_certificate", Tenant: "globex"}, true, false},
+		{"not mapped", &pkix.Name{CommonName: "other"}, Identity{}, true, true},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/v1/configuration", nil)
+		r.TLS = nil
+		if tt.subject != nil {
+			cert := writeCert(t, dir, "client", *tt.subject)
+			r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+		}
+		id, ok, err := certIdentity(r)
+		if ok != tt.ok || (err != nil) != tt.wantErr || id != tt.want {
+			t.Errorf("%s: got %+v, %v, %v, want %+v, %v", tt.name, id, ok, err, tt.want, tt.ok)
+		}
+	}
+
+	// A certificate presented but not verified is no identity.
+	r := httptest.NewRequest("GET", "/v1/configuration", nil)
+	r.TLS = &tls.ConnectionState{}
+	if _, ok, err := certIdentity(r); ok || err != nil {
+		t.Errorf("an unverified connection: %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,423 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+//The spans of the requests and actions follow the OpenTelemetry data model.
+//They are exported in batches with OTLP over HTTP, in its JSON encoding, or
+//written to a file one batch per line in the same encoding. The spans of an
+//action share its trace and carry its action.id attribute; the span of the
+//request that started it does too.
+
+const (
+	spanKindInternal = 1
+	spanKindServer   = 2
+	spanKindClient   = 3
+)
+
+// span is a timed operation of a trace. A nil span records nothing, which is
+// what startSpan returns while tracing is off.
+type span struct {
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	name     string
+	kind     int
+	start    time.Time
+
+	mu         sync.Mutex
+	endTime    time.Time
+	attributes map[string]interface{}
+	err        error
+}
+
+type spanKey struct{}
+
+func contextWithSpan(ctx context.Context, s *span) context.Context {
+	if s == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, spanKey{}, s)
+}
+
+func spanFromContext(ctx context.Context) *span {
+	s, _ := ctx.Value(spanKey{}).(*span)
+	return s
+}
+
+// startSpan starts a span, the child of the span of ctx when there is one.
+func startSpan(ctx context.Context, name string, kind int) (context.Context, *span) {
+	if tracer == nil {
+		return ctx, nil
+	}
+	s := &span{name: name, kind: kind, start: time.Now(), attributes: map[string]interface{}{}}
+	if parent := spanFromContext(ctx); parent != nil {
+		s.traceID = parent.traceID
+		s.parentID = parent.spanID
+	} else {
+		rand.Read(s.traceID[:])
+	}
+	rand.Read(s.spanID[:])
+	return contextWithSpan(ctx, s), s
+}
+
+// set sets an attribute of the span, a string, an int or a bool.
+func (s *span) set(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.attributes[key] = value
+	s.mu.Unlock()
+}
+
+// finish ends the span, failed when err is not nil, and queues it for export.
+func (s *span) finish(err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.endTime = time.Now()
+	s.err = err
+	s.mu.Unlock()
+	tracer.queue(s)
+}
+
+// parseTraceparent returns the remote parent of a W3C traceparent header.
+func parseTraceparent(h string) *span {
+	parts := strings.Split(h, "-")
+	if len(parts) != 4 || parts[0] != "00" {
+		return nil
+	}
+	var s span
+	t, err1 := hex.DecodeString(parts[1])
+	p, err2 := hex.DecodeString(parts[2])
+	if err1 != nil || err2 != nil || len(t) != len(s.traceID) || len(p) != len(s.spanID) {
+		return nil
+	}
+	copy(s.traceID[:], t)
+	copy(s.spanID[:], p)
+	return &s
+}
+
+// TracingMiddleware records a span for every request, continuing the trace of
+// the caller when it sends a traceparent header.
+func TracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tracer == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		route := r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if tpl, err := current.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+
+		ctx := contextWithSpan(r.Context(), parseTraceparent(r.Header.Get("traceparent")))
+		ctx, s := startSpan(ctx, r.Method+" "+route, spanKindServer)
+		s.set("http.method", r.Method)
+		s.set("http.route", route)
+		s.set("http.target", r.URL.Path)
+		if repoName := mux.Vars(r)["repo_name"]; repoName != "" {
+			s.set("config.name", repoName)
+		}
+
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		status := rec.status
+		if status == 0 {
+			status = 200
+		}
+		s.set("http.status_code", status)
+		var err error
+		if status >= 500 {
+			err = fmt.Errorf("%d %s", status, http.StatusText(status))
+		}
+		s.finish(err)
+	})
+}
+
+// startActionSpan starts the span of an action, the child of the span of ctx,
+// usually the request that started it.
+func startActionSpan(ctx context.Context, action, configName, actionID string) (context.Context, *span) {
+	spanFromContext(ctx).set("action.id", actionID)
+	ctx, s := startSpan(contextWithSpan(context.Background(), spanFromContext(ctx)), "action "+action, spanKindInternal)
+	if s == nil {
+		return ctx, nil
+	}
+	s.set("action.name", action)
+	s.set("action.id", actionID)
+	s.set("config.name", configName)
+	return ctx, s
+}
+
+// tracedStore records a span for the calls of an action to the store.
+type tracedStore struct {
+	Store
+	system string
+}
+
+func (t tracedStore) startSpan(actionID, op string) *span {
+	ctx := actionContext(actionID)
+	if spanFromContext(ctx) == nil {
+		return nil
+	}
+	_, s := startSpan(ctx, t.system+" "+op, spanKindClient)
+	s.set("db.system", t.system)
+	s.set("db.operation", op)
+	s.set("action.id", actionID)
+	return s
+}
+
+func (t tracedStore) InsertAction(a ActionResponse) error {
+	s := t.startSpan(a.ActionID, "InsertAction")
+	err := t.Store.InsertAction(a)
+	s.finish(err)
+	return err
+}
+
+func (t tracedStore) UpdateActionStatus(actionID, status string) error {
+	s := t.startSpan(actionID, "UpdateActionStatus")
+	err := t.Store.UpdateActionStatus(actionID, status)
+	s.finish(err)
+	return err
+}
+
+func (t tracedStore) FindAction(configName, actionID string) (ActionResponse, error) {
+	s := t.startSpan(actionID, "FindAction")
+	a, err := t.Store.FindAction(configName, actionID)
+	s.finish(err)
+	return a, err
+}
+
+// storeSystem names the database of the store in the spans.
+func storeSystem(s Store) string {
+	switch s := s.(type) {
+	case *mongoStore:
+		return "mongodb"
+	case *sqlStore:
+		if s.driver == "sqlite3" {
+			return "sqlite"
+		}
+		return "postgresql"
+	}
+	return "memory"
+}
+
+// spanExporter sends a batch of finished spans.
+type spanExporter interface {
+	export(body []byte) error
+}
+
+// otlpExporter posts the spans to an OTLP/HTTP endpoint.
+type otlpExporter struct {
+	url    string
+	client *http.Client
+}
+
+func (e otlpExporter) export(body []byte) error {
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s", e.url, resp.Status)
+	}
+	return nil
+}
+
+// fileExporter appends the spans to a file.
+type fileExporter struct {
+	f *os.File
+}
+
+func (e fileExporter) export(body []byte) error {
+	_, err := e.f.Write(append(body, '\n'))
+	return err
+}
+
+// spanBatcher exports the finished spans in batches.
+type spanBatcher struct {
+	exporter    spanExporter
+	serviceName string
+	spans       chan *span
+	done        chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// tracer exports the spans, nil while tracing is off.
+var tracer *spanBatcher
+
+var (
+	spanBatchSize     = 512
+	spanBatchInterval = 5 * time.Second
+)
+
+// setTracing starts exporting spans as configured.
+func setTracing(c TracingConfig) error {
+	var exporter spanExporter
+	switch c.Exporter {
+	case "otlp":
+		exporter = otlpExporter{
+			url:    strings.TrimSuffix(c.Endpoint, "/") + "/v1/traces",
+			client: &http.Client{Timeout: 10 * time.Second},
+		}
+	case "file":
+		f, err := os.OpenFile(c.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		exporter = fileExporter{f: f}
+	default:
+		return nil
+	}
+	tracer = &spanBatcher{
+		exporter:    exporter,
+		serviceName: c.ServiceName,
+		spans:       make(chan *span, 4*spanBatchSize),
+		done:        make(chan struct{}),
+	}
+	go tracer.run()
+	return nil
+}
+
+// ShutdownTracing exports the spans not exported yet.
+func ShutdownTracing() {
+	if tracer == nil {
+		return
+	}
+	tracer.mu.Lock()
+	tracer.closed = true
+	close(tracer.spans)
+	tracer.mu.Unlock()
+	<-tracer.done
+}
+
+// queue queues the span for export. It is dropped when the queue is full, or
+// once tracing is shut down, like the span of an action still running then.
+func (b *spanBatcher) queue(s *span) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	select {
+	case b.spans <- s:
+	default:
+	}
+}
+
+func (b *spanBatcher) run() {
+	defer close(b.done)
+	ticker := time.NewTicker(spanBatchInterval)
+	defer ticker.Stop()
+
+	var batch []*span
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		body, err := json.Marshal(b.otlpTraces(batch))
+		if err == nil {
+			err = b.exporter.export(body)
+		}
+		if err != nil {
+			Log.Error("Failed to export the spans", "spans", len(batch), "error", err)
+		}
+		batch = nil
+	}
+	for {
+		select {
+		case s, ok := <-b.spans:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, s)
+			if len(batch) >= spanBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func otlpAttribute(key string, value interface{}) map[string]interface{} {
+	var v map[string]interface{}
+	switch value := value.(type) {
+	case int:
+		v = map[string]interface{}{"intValue": strconv.Itoa(value)}
+	case bool:
+		v = map[string]interface{}{"boolValue": value}
+	default:
+		v = map[string]interface{}{"stringValue": fmt.Sprint(value)}
+	}
+	return map[string]interface{}{"key": key, "value": v}
+}
+
+// otlpTraces encodes the spans as an OTLP ExportTraceServiceRequest.
+func (b *spanBatcher) otlpTraces(batch []*span) map[string]interface{} {
+	spans := make([]map[string]interface{}, 0, len(batch))
+	for _, s := range batch {
+		s.mu.Lock()
+		attributes := make([]map[string]interface{}, 0, len(s.attributes))
+		for k, v := range s.attributes {
+			attributes = append(attributes, otlpAttribute(k, v))
+		}
+		status := map[string]interface{}{"code": 1}
+		if s.err != nil {
+			status = map[string]interface{}{"code": 2, "message": s.err.Error()}
+		}
+		otlpSpan := map[string]interface{}{
+			"traceId":           hex.EncodeToString(s.traceID[:]),
+			"spanId":            hex.EncodeToString(s.spanID[:]),
+			"name":              s.name,
+			"kind":              s.kind,
+			"startTimeUnixNano": strconv.FormatInt(s.start.UnixNano(), 10),
+			"endTimeUnixNano":   strconv.FormatInt(s.endTime.UnixNano(), 10),
+			"attributes":        attributes,
+			"status":            status,
+		}
+		if s.parentID != [8]byte{} {
+			otlpSpan["parentSpanId"] = hex.EncodeToString(s.parentID[:])
+		}
+		s.mu.Unlock()
+		spans = append(spans, otlpSpan)
+	}
+
+	return map[string]interface{}{
+		"resourceSpans": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{
+				"attributes": []interface{}{
+					otlpAttribute("service.name", b.serviceName),
+					otlpAttribute("service.version", Version),
+				},
+			},
+			"scopeSpans": []interface{}{map[string]interface{}{
+				"scope": map[string]interface{}{"name": "github.com/terraform-provider-ibm-api/utils"},
+				"spans": spans,
+			}},
+		}},
+	}
+}
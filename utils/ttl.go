@@ -0,0 +1,291 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// ttlPollInterval is how often the reaper looks for expired environments.
+var ttlPollInterval = time.Minute
+
+// defaultExpiringWindow is used by the expiring list when no window is given.
+var defaultExpiringWindow = 24 * time.Hour
+
+// TTLRequest -
+type TTLRequest struct {
+	TTL            string `json:"ttl,omitempty" description:"Time to live of the environment, e.g. 4h. It is destroyed when it expires"`
+	DeleteOnExpiry bool   `json:"delete_on_expiry,omitempty" description:"Remove the configuration after the expiry destroy succeeds"`
+}
+
+// TTLExtendRequest -
+type TTLExtendRequest struct {
+	ExtendBy string `json:"extend_by,required" description:"Duration added to the current expiry, e.g. 2h"`
+}
+
+// EnvironmentTTL -
+type EnvironmentTTL struct {
+	ConfigName     string    `json:"id" description:"Name of the configuration"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	DeleteOnExpiry bool      `json:"delete_on_expiry"`
+	Status         string    `json:"status" description:"Active, Destroying, Destroyed or Failed, the ttl of a deleted configuration is removed with it"`
+	Webhook        string    `json:"-"`
+	LogURL         string    `json:"-"`
+	LastActionID   string    `json:"last_action_id,omitempty" description:"ID of the destroy action run on expiry"`
+}
+
+// parseTTL validates a ttl duration given in a request.
+func parseTTL(ttl string) (time.Duration, error) {
+	d, err := time.ParseDuration(ttl)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("Invalid ttl %q, it must be a positive duration such as 30m or 4h", ttl)
+	}
+	return d, nil
+}
+
+// setTTL (re)starts the time to live of the configuration's environment.
+func setTTL(s *mgo.Session, repoName string, ttl time.Duration, deleteOnExpiry bool, logURL, webhook string) error {
+	session := s.Copy()
+	defer session.Close()
+	c := session.DB(dbName).C("environmentTTL")
+	_, err := c.Upsert(bson.M{"configname": repoName}, bson.M{"$set": bson.M{
+		"expiresat":      time.Now().Add(ttl),
+		"deleteonexpiry": deleteOnExpiry,
+		"status":         "Active",
+		"logurl":         logURL,
+		"webhook":        webhook,
+	}})
+	return err
+}
+
+// TTLHandler handles request to get the time to live of the configuration.
+func TTLHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session := s.Copy()
+		defer session.Close()
+
+		repoName := configOf(r)
+
+		var response EnvironmentTTL
+		c := session.DB(dbName).C("environmentTTL")
+		err := c.Find(bson.M{"configname": repoName}).One(&response)
+		if err == mgo.ErrNotFound {
+			http.Error(w, "There is no ttl for this configuration.", 404)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+
+		output, err := json.MarshalIndent(response, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		w.Header().Set("content-type", "application/json")
+		w.Write(output)
+	}
+}
+
+// TTLExtendHandler handles request to keep an environment alive for longer.
+func TTLExtendHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session := s.Copy()
+		defer session.Close()
+
+		repoName := configOf(r)
+
+		b, err := ioutil.ReadAll(r.Body)
+		defer r.Body.Close()
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+
+		var msg TTLExtendRequest
+		err = json.Unmarshal(b, &msg)
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		extendBy, err := parseTTL(msg.ExtendBy)
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+
+		var response EnvironmentTTL
+		c := session.DB(dbName).C("environmentTTL")
+		err = c.Find(bson.M{"configname": repoName}).One(&response)
+		if err == mgo.ErrNotFound {
+			http.Error(w, "There is no ttl for this configuration.", 404)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+
+		expiresAt := response.ExpiresAt
+		if expiresAt.Before(time.Now()) {
+			expiresAt = time.Now()
+		}
+		expiresAt = expiresAt.Add(extendBy)
+
+		// Only an active environment can be extended, the reaper may already be destroying it.
+		err = c.Update(bson.M{"configname": repoName, "status": "Active", "expiresat": response.ExpiresAt}, bson.M{"$set": bson.M{"expiresat": expiresAt}})
+		if err == mgo.ErrNotFound {
+			http.Error(w, fmt.Sprintf("The environment can not be extended, it is %s.", response.Status), 409)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		response.ExpiresAt = expiresAt
+
+		output, err := json.MarshalIndent(response, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		w.Header().Set("content-type", "application/json")
+		w.Write(output)
+	}
+}
+
+// ExpiringHandler handles request to list the environments that expire soon.
+func ExpiringHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session := s.Copy()
+		defer session.Close()
+
+		within := defaultExpiringWindow
+		if v := r.URL.Query().Get("within"); v != "" {
+			var err error
+			within, err = parseTTL(v)
+			if err != nil {
+				http.Error(w, err.Error(), 400)
+				return
+			}
+		}
+
+		all, visible, err := visibleConfigs(r)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		selector := bson.M{"status": "Active", "expiresat": bson.M{"$lte": time.Now().Add(within)}}
+		if all {
+			selector["configname"] = tenantConfigs(tenantOf(r))
+		} else {
+			selector["configname"] = bson.M{"$in": visible}
+		}
+
+		expiring := []EnvironmentTTL{}
+		c := session.DB(dbName).C("environmentTTL")
+		err = c.Find(selector).Sort("expiresat").All(&expiring)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+
+		output, err := json.MarshalIndent(expiring, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		w.Header().Set("content-type", "application/json")
+		w.Write(output)
+	}
+}
+
+// StartTTLReaper destroys environments whose time to live has expired.
+func StartTTLReaper(s *mgo.Session) {
+	go func() {
+		ticker := time.NewTicker(ttlPollInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			reapExpired(s)
+		}
+	}()
+}
+
+func reapExpired(s *mgo.Session) {
+	session := s.Copy()
+	defer session.Close()
+
+	var expired []EnvironmentTTL
+	c := session.DB(dbName).C("environmentTTL")
+	err := c.Find(bson.M{"status": "Active", "expiresat": bson.M{"$lte": time.Now()}}).All(&expired)
+	if err != nil {
+		Log.Error("Failed to load the expired environments", "error", err)
+		return
+	}
+
+	for _, env := range expired {
+		ctx := withLogFields(context.Background(), "config", env.ConfigName)
+		// Claim the environment so a concurrent extend or another server does not race the destroy.
+		err = c.Update(bson.M{"configname": env.ConfigName, "status": "Active", "expiresat": env.ExpiresAt}, bson.M{"$set": bson.M{"status": "Destroying"}})
+		if err == mgo.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			Log.ErrorContext(ctx, "Failed to update the environment ttl", "error", err)
+			continue
+		}
+
+		if err := destroyPrevented(env.ConfigName); err != nil {
+			Log.WarnContext(ctx, "TTL expired, not destroying", "error", err)
+			err = c.Update(bson.M{"configname": env.ConfigName}, bson.M{"$set": bson.M{"status": "Protected"}})
+			if err != nil {
+				Log.ErrorContext(ctx, "Failed to update the environment ttl", "error", err)
+			}
+			continue
+		}
+
+		Log.InfoContext(ctx, "TTL expired, destroying the configuration")
+		env := env
+		actionResponse := startAction(ctx, s, env.ConfigName, "destroy", env.LogURL, env.Webhook, func(result ActionResponse) {
+			finishExpiry(s, env, result.Status)
+		})
+		err = c.Update(bson.M{"configname": env.ConfigName}, bson.M{"$set": bson.M{"lastactionid": actionResponse.ActionID}})
+		if err != nil {
+			Log.ErrorContext(ctx, "Failed to update the environment ttl", "error", err)
+		}
+	}
+}
+
+// finishExpiry records the result of the expiry destroy and removes the
+// configuration when asked to.
+func finishExpiry(s *mgo.Session, env EnvironmentTTL, status string) {
+	session := s.Copy()
+	defer session.Close()
+
+	ttlStatus := "Failed"
+	if status == "Completed" {
+		ttlStatus = "Destroyed"
+		if env.DeleteOnExpiry {
+			// The configuration goes away like on DELETE, its ttl included.
+			err := deleteConfiguration(s, env.ConfigName)
+			if err == nil {
+				Log.Info("Deleted the expired configuration", "config", env.ConfigName)
+				return
+			}
+			Log.Error("Failed to remove the expired configuration", "config", env.ConfigName, "error", err)
+		}
+	}
+
+	c := session.DB(dbName).C("environmentTTL")
+	err := c.Update(bson.M{"configname": env.ConfigName}, bson.M{"$set": bson.M{"status": ttlStatus}})
+	if err != nil {
+		Log.Error("Failed to update the environment ttl", "config", env.ConfigName, "error", err)
+	}
+}
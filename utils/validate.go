@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Identifiers taken from requests end up in file paths and db queries, they
+// are all checked here before any handler runs.
+var (
+	configNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,99}$`)
+	idPattern         = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+	actionPattern     = regexp.MustCompile(`^[a-z][a-z_-]{0,31}$`)
+	logFilePattern    = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}\.(out|err)$`)
+	subjectPattern    = regexp.MustCompile(`^[^/\\\x00-\x1f]{1,256}$`)
+)
+
+// pathVarPatterns are the patterns of the route variables.
+var pathVarPatterns = map[string]*regexp.Regexp{
+	"repo_name":   configNamePattern,
+	"action":      actionPattern,
+	"actionID":    idPattern,
+	"log_file":    logFilePattern,
+	"schedule_id": idPattern,
+	"webhook_id":  idPattern,
+	"channel_id":  idPattern,
+	"key_id":      idPattern,
+	"provider":    actionPattern,
+	"subject":     subjectPattern,
+}
+
+// validateConfigName checks a configuration name, as given in urls or taken
+// from a git url.
+func validateConfigName(name string) error {
+	if !configNamePattern.MatchString(name) {
+		return fmt.Errorf("Invalid configuration name %q, it must be letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+// validateStoredName checks the name a configuration is stored under, see qualifiedName.
+func validateStoredName(name string) error {
+	if i := strings.Index(name, "/"); i >= 0 {
+		if err := validateTenant(name[:i]); err != nil {
+			return err
+		}
+		name = name[i+1:]
+	}
+	return validateConfigName(name)
+}
+
+// validatePathVars checks every variable of the matched route.
+func validatePathVars(vars map[string]string) error {
+	for name, value := range vars {
+		pattern, ok := pathVarPatterns[name]
+		if !ok {
+			return fmt.Errorf("Unexpected path parameter %s", name)
+		}
+		if !pattern.MatchString(value) {
+			return fmt.Errorf("Invalid %s %q", name, value)
+		}
+	}
+	return nil
+}
+
+// ValidateMiddleware rejects requests whose path parameters are not valid
+// identifiers, like traversal sequences or encoded slashes.
+func ValidateMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(strings.ToLower(r.URL.RawPath), "%2f") || strings.Contains(strings.ToLower(r.URL.RawPath), "%5c") {
+			http.Error(w, "Encoded slashes are not allowed in the path", 400)
+			return
+		}
+		if err := validatePathVars(mux.Vars(r)); err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withinDir joins name onto dir and fails when the result is not inside dir.
+func withinDir(dir, name string) (string, error) {
+	p := filepath.Join(dir, name)
+	rel, err := filepath.Rel(dir, p)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%q is outside %s", name, dir)
+	}
+	return p, nil
+}
@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestValidateMiddleware(t *testing.T) {
+	r := mux.NewRouter()
+	r.Use(ValidateMiddleware)
+	var reached bool
+	ok := func(w http.ResponseWriter, r *http.Request) { reached = true }
+	r.HandleFunc("/v1/configuration/{repo_name}", ok)
+	r.HandleFunc("/v1/configuration/{repo_name}/{action}/{actionID}/log", ok)
+	r.HandleFunc("/v1/configuration/{repo_name}/{action}/{log_file}", ok)
+	r.HandleFunc("/v1/configuration/{repo_name}/{action}", ok)
+
+	tests := []struct {
+		path    string
+		code    int
+		reached bool
+	}{
+		{"/v1/configuration/repo", 200, true},
+		{"/v1/configuration/my.repo_1-x/plan", 200, true},
+		{"/v1/configuration/repo/plan/a1b2c3.out", 200, true},
+		{"/v1/configuration/repo/plan/a1b2c3/log", 200, true},
+
+		// Traversal is cleaned by the router and never reaches a handler.
+		{"/v1/configuration/..", 301, false},
+		{"/v1/configuration/repo/../../etc", 301, false},
+		{"/v1/configuration/%2e%2e", 301, false},
+		{"/v1/configuration/repo/plan/%2e%2e", 301, false},
+		{"/v1/configuration/repo/plan/..%2F..%2Fetc%2Fpasswd", 301, false},
+		{"/v1/configuration/repo/plan/%2Fetc%2Fpasswd", 301, false},
+
+		// Encoded slashes would hide a traversal inside one variable.
+		{"/v1/configuration/a%2Fb", 400, false},
+		{"/v1/configuration/a%2fb", 400, false},
+		{"/v1/configuration/repo%2Fplan/a1b2c3.out", 400, false},
+		{"/v1/configuration/repo/plan/..%5C..%5Cwin.ini", 400, false},
+
+		// Absolute paths and other names fail the patterns of the variables.
+		{"/v1/configuration/.hidden", 400, false},
+		{"/v1/configuration/repo/plan/passwd", 400, false},
+		{"/v1/configuration/repo/plan/..out", 400, false},
+		{"/v1/configuration/repo/plan/x.tfstate", 400, false},
+		{"/v1/configuration/repo/PLAN", 400, false},
+		{"/v1/configuration/repo/plan/a.b/log", 400, false},
+		{"/v1/configuration/" + strings.Repeat("a", 101), 400, false},
+	}
+	for _, tt := range tests {
+		reached = false
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.Code != tt.code || reached != tt.reached {
+			t.Errorf("GET %s: got %d, reached %v, want %d, reached %v", tt.path, w.Code, reached, tt.code, tt.reached)
+		}
+	}
+}
+
+func TestWithinDir(t *testing.T) {
+	tests := []struct {
+		dir, name string
+		want      string
+		ok        bool
+	}{
+		{"/data/log", "a1b2.out", "/data/log/a1b2.out", true},
+		{"/data/log", "sub/a1b2.out", "/data/log/sub/a1b2.out", true},
+		{"/data/log", "sub/../a1b2.out", "/data/log/a1b2.out", true},
+		{"/data/log", "/etc/passwd", "/data/log/etc/passwd", true},
+		{"/data/log", "", "", false},
+		{"/data/log", ".", "", false},
+		{"/data/log", "..", "", false},
+		{"/data/log", "../state/repo.tfstate", "", false},
+		{"/data/log", "sub/../../log2/a", "", false},
+		{"/data/log", "../../etc/passwd", "", false},
+	}
+	for _, tt := range tests {
+		got, err := withinDir(tt.dir, tt.name)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("withinDir(%q, %q) = %q, %v, want %q, ok %v", tt.dir, tt.name, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestValidateConfigName(t *testing.T) {
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"repo", true},
+		{"my.repo_1-x", true},
+		{"0repo", true},
+		{strings.Repeat("a", 100), true},
+		{"", false},
+		{".", false},
+		{"..", false},
+		{".hidden", false},
+		{"-repo", false},
+		{"a/b", false},
+		{"../repo", false},
+		{"/etc/passwd", false},
+		{`a\b`, false},
+		{"a b", false},
+		{"repo\x00", false},
+		{"%2e%2e", false},
+		{strings.Repeat("a", 101), false},
+	}
+	for _, tt := range tests {
+		if err := validateConfigName(tt.name); (err == nil) != tt.ok {
+			t.Errorf("validateConfigName(%q) = %v, want ok %v", tt.name, err, tt.ok)
+		}
+	}
+}
+
+func TestValidateStoredName(t *testing.T) {
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"repo", true},
+		{"acme/repo", true},
+		{"Acme/repo", false},
+		{"../repo", false},
+		{"acme/../repo", false},
+		{"acme/repo/x", false},
+		{"/repo", false},
+	}
+	for _, tt := range tests {
+		if err := validateStoredName(tt.name); (err == nil) != tt.ok {
+			t.Errorf("validateStoredName(%q) = %v, want ok %v", tt.name, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,516 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/mux"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Event types sent to the webhook targets.
+const (
+	EventActionStarted   = "action.started"
+	EventActionCompleted = "action.completed"
+	EventActionFailed    = "action.failed"
+)
+
+var eventTypes = map[string]bool{EventActionStarted: true, EventActionCompleted: true, EventActionFailed: true}
+
+// webhookMaxAttempts is how many times a delivery is tried before it is marked failed.
+var webhookMaxAttempts = 5
+
+// webhookBackoff is the wait before the first retry, doubled for every further retry.
+var webhookBackoff = 2 * time.Second
+
+// webhookRetryInterval is how often the deliveries due for a retry are looked for.
+var webhookRetryInterval = time.Second
+
+// webhookLease is how long a delivery being attempted is left to its attempt
+// before it is tried again, longer than the timeout of the attempt.
+var webhookLease = time.Minute
+
+// webhookAllowedNetworks are the networks webhooks may reach although they
+// are not public, set by Configure.
+var webhookAllowedNetworks []*net.IPNet
+
+// sharedAddressSpace is the carrier-grade NAT range, where some clouds serve
+// their metadata.
+var _, sharedAddressSpace, _ = net.ParseCIDR("100.64.0.0/10")
+
+// The connections of the webhooks are checked once the address is resolved,
+// so that a name resolving to a private address later is refused as well.
+var webhookHTTPClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: func(network, address string, c syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				if ip := net.ParseIP(host); ip == nil || !webhookAddressAllowed(ip) {
+					return fmt.Errorf("webhooks may not be delivered to %s", host)
+				}
+				return nil
+			},
+		}).DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+	},
+}
+
+// Event -
+type Event struct {
+	EventID    string    `json:"event_id"`
+	Type       string    `json:"type" description:"action.started, action.completed or action.failed"`
+	ConfigName string    `json:"id" description:"Name of the configuration"`
+	Action     string    `json:"action"`
+	ActionID   string    `json:"action_id"`
+	Status     string    `json:"status"`
+	OutputURL  string    `json:"output_url,omitempty"`
+	ErrorURL   string    `json:"error_url,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// WebhookRequest -
+type WebhookRequest struct {
+	URL    string   `json:"url,required" description:"URL the events are posted to"`
+	Secret string   `json:"secret,omitempty" description:"Key of the HMAC-SHA256 signature sent in the X-Signature-256 header"`
+	Events []string `json:"events,omitempty" description:"Event types to send. Defaults to all"`
+}
+
+// WebhookTarget -
+type WebhookTarget struct {
+	WebhookID  string   `json:"webhook_id"`
+	ConfigName string   `json:"id" description:"Name of the configuration"`
+	URL        string   `json:"url"`
+	Secret     string   `json:"-"`
+	Events     []string `json:"events"`
+	Timestamp  string   `json:"timestamp"`
+}
+
+// WebhookDelivery -
+type WebhookDelivery struct {
+	DeliveryID   string     `json:"delivery_id"`
+	WebhookID    string     `json:"webhook_id"`
+	ConfigName   string     `json:"id"`
+	EventID      string     `json:"event_id"`
+	EventType    string     `json:"event_type"`
+	ActionID     string     `json:"action_id"`
+	Status       string     `json:"status" description:"Pending, Delivered or Failed"`
+	Attempts     int        `json:"attempts"`
+	ResponseCode int        `json:"response_code,omitempty"`
+	Error        string     `json:"error,omitempty"`
+	Created      time.Time  `json:"created"`
+	Updated      time.Time  `json:"updated"`
+	NextAttempt  *time.Time `json:"next_attempt,omitempty" description:"When a pending delivery is tried again"`
+	Event        Event      `json:"-"`
+}
+
+func (t WebhookTarget) wants(eventType string) bool {
+	if len(t.Events) == 0 {
+		return true
+	}
+	for _, e := range t.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookCreateHandler handles request to register a webhook target for the configuration.
+func WebhookCreateHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session := s.Copy()
+		defer session.Close()
+
+		repoName := configOf(r)
+
+		b, err := ioutil.ReadAll(r.Body)
+		defer r.Body.Close()
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+
+		var msg WebhookRequest
+		err = json.Unmarshal(b, &msg)
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		u, err := url.Parse(msg.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			http.Error(w, fmt.Sprintf("Invalid url %q", msg.URL), 400)
+			return
+		}
+		if err := checkWebhookHost(u.Hostname()); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid url %q: %v", msg.URL, err), 400)
+			return
+		}
+		for _, e := range msg.Events {
+			if !eventTypes[e] {
+				http.Error(w, fmt.Sprintf("Invalid event %q", e), 400)
+				return
+			}
+		}
+
+		target := WebhookTarget{
+			WebhookID:  newActionID(),
+			ConfigName: repoName,
+			URL:        msg.URL,
+			Secret:     msg.Secret,
+			Events:     msg.Events,
+			Timestamp:  time.Now().Format("20060102150405"),
+		}
+
+		c := session.DB(dbName).C("webhookTargets")
+		err = c.Insert(target)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+
+		output, err := json.MarshalIndent(target, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(201)
+		w.Write(output)
+	}
+}
+
+// WebhookListHandler handles request to list the webhook targets of the configuration.
+func WebhookListHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session := s.Copy()
+		defer session.Close()
+
+		repoName := configOf(r)
+
+		targets := []WebhookTarget{}
+		c := session.DB(dbName).C("webhookTargets")
+		err := c.Find(bson.M{"configname": repoName}).All(&targets)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+
+		output, err := json.MarshalIndent(targets, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		w.Header().Set("content-type", "application/json")
+		w.Write(output)
+	}
+}
+
+// WebhookDeleteHandler handles request to remove a webhook target.
+func WebhookDeleteHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session := s.Copy()
+		defer session.Close()
+
+		vars := mux.Vars(r)
+		repoName := configOf(r)
+		webhookID := vars["webhook_id"]
+
+		c := session.DB(dbName).C("webhookTargets")
+		err := c.Remove(bson.M{"configname": repoName, "webhookid": webhookID})
+		if err == mgo.ErrNotFound {
+			http.Error(w, "There is no webhook for this request.", 404)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+	}
+}
+
+// WebhookDeliveriesHandler handles request to get the delivery log of a webhook target.
+func WebhookDeliveriesHandler(s *mgo.Session) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session := s.Copy()
+		defer session.Close()
+
+		vars := mux.Vars(r)
+		query := bson.M{"configname": configOf(r), "webhookid": vars["webhook_id"]}
+		if status := r.URL.Query().Get("status"); status != "" {
+			query["status"] = status
+		}
+
+		deliveries := []WebhookDelivery{}
+		c := session.DB(dbName).C("webhookDeliveries")
+		err := c.Find(query).Sort("-created").Limit(100).All(&deliveries)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+
+		output, err := json.MarshalIndent(deliveries, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		w.Header().Set("content-type", "application/json")
+		w.Write(output)
+	}
+}
+
+// publishEvent sends the event to every webhook target of its configuration
+// that subscribed to the event type. Deliveries run in the background.
+func publishEvent(s *mgo.Session, event Event) {
+	if s == nil {
+		return
+	}
+	session := s.Copy()
+	defer session.Close()
+
+	event.EventID = newActionID()
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	var targets []WebhookTarget
+	c := session.DB(dbName).C("webhookTargets")
+	err := c.Find(bson.M{"configname": event.ConfigName}).All(&targets)
+	if err != nil {
+		Log.Error("Failed to load the webhook targets", "config", event.ConfigName, "action_id", event.ActionID, "error", err)
+		return
+	}
+	for _, t := range targets {
+		if t.wants(event.Type) {
+			go deliverEvent(s, t, event)
+		}
+	}
+}
+
+// deliverEvent records the delivery of the event to the target and makes its
+// first attempt. The retries are made by StartWebhookRetrier from the
+// delivery log, so they survive a restart of the server.
+func deliverEvent(s *mgo.Session, t WebhookTarget, event Event) {
+	session := s.Copy()
+	defer session.Close()
+
+	// The first attempt is made here, the retrier leaves it alone meanwhile.
+	lease := time.Now().Add(webhookLease)
+	delivery := WebhookDelivery{
+		DeliveryID:  newActionID(),
+		WebhookID:   t.WebhookID,
+		ConfigName:  t.ConfigName,
+		EventID:     event.EventID,
+		EventType:   event.Type,
+		ActionID:    event.ActionID,
+		Status:      "Pending",
+		Created:     time.Now(),
+		Updated:     time.Now(),
+		NextAttempt: &lease,
+		Event:       event,
+	}
+	c := session.DB(dbName).C("webhookDeliveries")
+	err := c.Insert(delivery)
+	if err != nil {
+		// Without a record the delivery gets its first attempt only.
+		Log.Error("Failed to record the webhook delivery", "config", event.ConfigName, "action_id", event.ActionID,
+			"webhook_id", t.WebhookID, "error", err)
+		attemptDelivery(t, &delivery)
+		return
+	}
+	attemptDelivery(t, &delivery)
+	saveDelivery(c, delivery)
+}
+
+// StartWebhookRetrier tries the pending deliveries again once their backoff
+// is over, those left pending by a previous run of the server included.
+func StartWebhookRetrier(s *mgo.Session) {
+	go func() {
+		ticker := time.NewTicker(webhookRetryInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			retryDeliveries(s)
+		}
+	}()
+}
+
+func retryDeliveries(s *mgo.Session) {
+	session := s.Copy()
+	defer session.Close()
+
+	var deliveries []WebhookDelivery
+	c := session.DB(dbName).C("webhookDeliveries")
+	err := c.Find(dueDeliveries(bson.M{})).Select(bson.M{"deliveryid": 1}).All(&deliveries)
+	if err != nil {
+		Log.Error("Failed to load the pending webhook deliveries", "error", err)
+		return
+	}
+	for _, d := range deliveries {
+		// Claim the delivery, another server may be retrying it.
+		var delivery WebhookDelivery
+		_, err := c.Find(dueDeliveries(bson.M{"deliveryid": d.DeliveryID})).Apply(mgo.Change{
+			Update:    bson.M{"$set": bson.M{"nextattempt": time.Now().Add(webhookLease)}},
+			ReturnNew: true,
+		}, &delivery)
+		if err == mgo.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			Log.Error("Failed to claim the webhook delivery", "delivery_id", d.DeliveryID, "error", err)
+			continue
+		}
+
+		var t WebhookTarget
+		err = c.Database.C("webhookTargets").Find(bson.M{"webhookid": delivery.WebhookID}).One(&t)
+		switch {
+		case err == mgo.ErrNotFound:
+			delivery.Status, delivery.Error, delivery.Updated, delivery.NextAttempt = "Failed", "the webhook was removed", time.Now(), nil
+			saveDelivery(c, delivery)
+		case err != nil:
+			Log.Error("Failed to load the webhook target", "webhook_id", delivery.WebhookID, "error", err)
+		case delivery.Event.EventID == "":
+			delivery.Status, delivery.Error, delivery.Updated, delivery.NextAttempt = "Failed", "the event of the delivery was not kept", time.Now(), nil
+			saveDelivery(c, delivery)
+		default:
+			go func(t WebhookTarget, delivery WebhookDelivery) {
+				session := s.Copy()
+				defer session.Close()
+				attemptDelivery(t, &delivery)
+				saveDelivery(session.DB(dbName).C("webhookDeliveries"), delivery)
+			}(t, delivery)
+		}
+	}
+}
+
+// dueDeliveries adds to the query the pending deliveries due for an attempt,
+// those recorded before the deliveries had a next attempt included.
+func dueDeliveries(query bson.M) bson.M {
+	query["status"] = "Pending"
+	query["$or"] = []bson.M{
+		{"nextattempt": bson.M{"$lte": time.Now()}},
+		{"nextattempt": bson.M{"$exists": false}},
+	}
+	return query
+}
+
+// attemptDelivery posts the event of the delivery to the target and records
+// the outcome in the delivery. A delivery that may succeed later stays
+// Pending until its next attempt, the wait doubling every attempt.
+func attemptDelivery(t WebhookTarget, d *WebhookDelivery) {
+	d.Attempts++
+	d.Updated = time.Now()
+	d.Error = ""
+
+	body, err := json.Marshal(d.Event)
+	if err != nil {
+		d.Status, d.Error, d.NextAttempt = "Failed", err.Error(), nil
+		return
+	}
+	code, err := postWebhook(t, d.DeliveryID, d.EventType, body)
+	d.ResponseCode = code
+
+	retry := false
+	switch {
+	case err != nil:
+		d.Error = err.Error()
+		retry = true
+	case code >= 500 || code == 429:
+		d.Error = fmt.Sprintf("%s returned %d", t.URL, code)
+		retry = true
+	case code >= 300:
+		d.Error = fmt.Sprintf("%s returned %d", t.URL, code)
+	}
+	switch {
+	case d.Error == "":
+		d.Status = "Delivered"
+		d.NextAttempt = nil
+	case !retry || d.Attempts >= webhookMaxAttempts:
+		d.Status = "Failed"
+		d.NextAttempt = nil
+	default:
+		next := d.Updated.Add(webhookBackoff << uint(d.Attempts-1))
+		d.NextAttempt = &next
+	}
+}
+
+func saveDelivery(c *mgo.Collection, d WebhookDelivery) {
+	err := c.Update(bson.M{"deliveryid": d.DeliveryID}, d)
+	if err != nil {
+		Log.Error("Failed to record the webhook delivery", "config", d.ConfigName, "action_id", d.ActionID,
+			"webhook_id", d.WebhookID, "delivery_id", d.DeliveryID, "error", err)
+	}
+}
+
+func postWebhook(t WebhookTarget, deliveryID, eventType string, body []byte) (int, error) {
+	req, err := http.NewRequest("POST", t.URL, bytes.NewBuffer(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event", eventType)
+	req.Header.Set("X-Delivery", deliveryID)
+	if t.Secret != "" {
+		req.Header.Set("X-Signature-256", "sha256="+signPayload(t.Secret, body))
+	}
+
+	resp, err := webhookHTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// checkWebhookHost returns an error when the host, or an address it resolves
+// to, may not be reached by the webhooks.
+func checkWebhookHost(host string) error {
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		var err error
+		ips, err = net.LookupIP(host)
+		if err != nil {
+			return err
+		}
+	}
+	for _, ip := range ips {
+		if !webhookAddressAllowed(ip) {
+			return fmt.Errorf("%s is a loopback, private or link-local address", ip)
+		}
+	}
+	return nil
+}
+
+// webhookAddressAllowed is whether the webhooks may reach ip: the public
+// addresses and those of webhookAllowedNetworks. The link-local addresses
+// have the metadata services of the clouds.
+func webhookAddressAllowed(ip net.IP) bool {
+	for _, network := range webhookAllowedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified() || ip.IsMulticast() || sharedAddressSpace.Contains(ip))
+}
+
+// signPayload returns the hex encoded HMAC-SHA256 of body keyed with secret.
+func signPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
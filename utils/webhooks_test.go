@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignPayload(t *testing.T) {
+	got := signPayload("key", []byte("The quick brown fox jumps over the lazy dog"))
+	if want := "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestWebhookAddressAllowed(t *testing.T) {
+	defer func(n []*net.IPNet) { webhookAllowedNetworks = n }(webhookAllowedNetworks)
+	_, internal, _ := net.ParseCIDR("10.20.0.0/16")
+
+	tests := []struct {
+		host    string
+		allowed []*net.IPNet
+		want    bool
+	}{
+		{"93.184.216.34", nil, true},
+		{"2606:2800:220:1:248:1893:25c8:1946", nil, true},
+		{"127.0.0.1", nil, false},
+		{"::1", nil, false},
+		{"10.20.1.2", nil, false},
+		{"172.16.0.1", nil, false},
+		{"192.168.1.1", nil, false},
+		{"169.254.169.254", nil, false},
+		{"fe80::1", nil, false},
+		{"fd00:ec2::254", nil, false},
+		{"100.100.100.200", nil, false},
+		{"0.0.0.0", nil, false},
+		{"::ffff:127.0.0.1", nil, false},
+		{"10.20.1.2", []*net.IPNet{internal}, true},
+		{"10.21.1.2", []*net.IPNet{internal}, false},
+		{"localhost", nil, false},
+	}
+	for _, tt := range tests {
+		webhookAllowedNetworks = tt.allowed
+		err := checkWebhookHost(tt.host)
+		if (err == nil) != tt.want {
+			t.Errorf("%s with %v: got %v, want allowed %v", tt.host, tt.allowed, err, tt.want)
+		}
+	}
+}
+
+func TestAttemptDelivery(t *testing.T) {
+	defer func(n []*net.IPNet) { webhookAllowedNetworks = n }(webhookAllowedNetworks)
+	defer func(max int) { webhookMaxAttempts = max }(webhookMaxAttempts)
+	webhookMaxAttempts = 3
+
+	codes := []int{}
+	var got *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(codes[0])
+		codes = codes[1:]
+	}))
+	defer srv.Close()
+
+	target := WebhookTarget{WebhookID: "w1", ConfigName: "infra", URL: srv.URL, Secret: "s3cret"}
+	newDelivery := func() *WebhookDelivery {
+		return &WebhookDelivery{DeliveryID: "d1", WebhookID: "w1", EventType: EventActionFailed, Status: "Pending",
+			Event: Event{EventID: "e1", Type: EventActionFailed, ConfigName: "infra", ActionID: "a1", Status: "Failed"}}
+	}
+
+	// The test server listens on loopback, which is refused unless allowed.
+	webhookAllowedNetworks = nil
+	d := newDelivery()
+	attemptDelivery(target, d)
+	if d.Status != "Pending" || !strings.Contains(d.Error, "may not be delivered") || got != nil {
+		t.Errorf("the delivery to loopback is %+v", d)
+	}
+
+	_, loopback, _ := net.ParseCIDR("127.0.0.0/8")
+	webhookAllowedNetworks = []*net.IPNet{loopback}
+
+	// Retried with a doubling wait until delivered.
+	codes = []int{503, 429, 200}
+	d = newDelivery()
+	for i, want := range []struct {
+		status string
+		code   int
+		wait   int
+	}{{"Pending", 503, 1}, {"Pending", 429, 2}, {"Delivered", 200, 0}} {
+		attemptDelivery(target, d)
+		if d.Status != want.status || d.Attempts != i+1 || d.ResponseCode != want.code {
+			t.Errorf("attempt %d: got %+v, want %s with %d", i+1, d, want.status, want.code)
+		}
+		if want.wait == 0 && d.NextAttempt != nil || want.wait != 0 && (d.NextAttempt == nil || d.NextAttempt.Sub(d.Updated) != webhookBackoff*time.Duration(want.wait)) {
+			t.Errorf("attempt %d: next attempt %v after %v, want a wait of %d backoffs", i+1, d.NextAttempt, d.Updated, want.wait)
+		}
+	}
+	if d.Error != "" {
+		t.Errorf("the delivered delivery has the error %q", d.Error)
+	}
+
+	// The event is signed with the secret of the target.
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil || event.EventID != "e1" {
+		t.Errorf("posted %s, %v", body, err)
+	}
+	if sig := got.Header.Get("X-Signature-256"); sig != "sha256="+signPayload("s3cret", body) {
+		t.Errorf("signature %q does not match the body", sig)
+	}
+	if got.Header.Get("X-Event") != EventActionFailed || got.Header.Get("X-Delivery") != "d1" {
+		t.Errorf("headers %v", got.Header)
+	}
+
+	// A client error is not retried, a server error is given up after the last attempt.
+	codes = []int{404, 500, 500, 500}
+	d = newDelivery()
+	attemptDelivery(target, d)
+	if d.Status != "Failed" || d.Attempts != 1 || d.NextAttempt != nil || !strings.Contains(d.Error, "404") {
+		t.Errorf("the refused delivery is %+v", d)
+	}
+	d = newDelivery()
+	for i := 0; i < webhookMaxAttempts; i++ {
+		attemptDelivery(target, d)
+	}
+	if d.Status != "Failed" || d.Attempts != webhookMaxAttempts || d.NextAttempt != nil {
+		t.Errorf("the delivery after %d failed attempts is %+v", webhookMaxAttempts, d)
+	}
+
+	// Without a secret nothing is signed.
+	codes = []int{200}
+	target.Secret = ""
+	attemptDelivery(target, newDelivery())
+	if _, ok := got.Header["X-Signature-256"]; ok {
+		t.Error("a delivery without a secret is signed")
+	}
+}